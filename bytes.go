@@ -0,0 +1,79 @@
+package hocon
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+const (
+	// encodingBase64 decodes bytes from standard or URL base64, padded or not. It is the default encoding.
+	encodingBase64 = "base64"
+	// encodingHex decodes bytes from hexadecimal string.
+	encodingHex = "hex"
+	// encodingPlain takes bytes of the string as is.
+	encodingPlain = "plain"
+)
+
+// isBytesType tells if the type is a slice or an array of bytes which are decoded from a string.
+func isBytesType(typ reflect.Type) bool {
+	return (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && typ.Elem().Kind() == reflect.Uint8
+}
+
+// bytesEncoding returns the encoding of the field given by the tag, base64 by default.
+func bytesEncoding(tagMap map[string]string) string {
+	if encoding, exists := tagMap[encodingKey]; exists {
+		return encoding
+	}
+	return encodingBase64
+}
+
+// parseBytes decodes the string value to the slice or the array of bytes. The length of the array must match
+// the number of decoded bytes.
+func parseBytes(typ reflect.Type, hoconValue value, encoding string) (reflect.Value, error) {
+	rawValue, ok := text(hoconValue)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("cannot use %s as bytes", typeName(hoconValue))
+	}
+
+	var data []byte
+	var err error
+	switch encoding {
+	case encodingBase64:
+		data, err = decodeBase64(rawValue)
+	case encodingHex:
+		data, err = hex.DecodeString(rawValue)
+	case encodingPlain:
+		data = []byte(rawValue)
+	default:
+		return reflect.Value{}, fmt.Errorf("unknown encoding %s, known ones are %s, %s, %s", encoding,
+			encodingBase64, encodingHex, encodingPlain)
+	}
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("cannot decode %s: %w", encoding, err)
+	}
+
+	if typ.Kind() == reflect.Slice {
+		return reflect.ValueOf(data).Convert(typ), nil
+	}
+	if len(data) != typ.Len() {
+		return reflect.Value{}, fmt.Errorf("cannot use %d bytes as %s", len(data), typ)
+	}
+	array := reflect.New(typ).Elem()
+	reflect.Copy(array, reflect.ValueOf(data))
+	return array, nil
+}
+
+// encodeBytes renders the slice or the array of bytes as a string of given encoding.
+func encodeBytes(v reflect.Value, encoding string) string {
+	data := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(data), v)
+	switch encoding {
+	case encodingHex:
+		return hex.EncodeToString(data)
+	case encodingPlain:
+		return string(data)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
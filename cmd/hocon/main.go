@@ -0,0 +1,105 @@
+// Command hocon is a helper for go-hocon configurations.
+//
+// Usage:
+//
+//	hocon keygen [-size 32]
+//	hocon encrypt [-key base64-key] [value]
+//
+// keygen prints a new random base64-encoded key. encrypt prints the value encrypted as `ENC(...)`, the value
+// is read from standard input if it is not given as an argument. The key is taken from -key flag or from
+// HOCON_ENCRYPTION_KEY environment variable.
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	hocon "github.com/artemkaxboy/go-hocon"
+)
+
+const usage = `usage:
+  hocon keygen [-size 32]
+  hocon encrypt [-key base64-key] [value]
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "keygen":
+		err = keygen(os.Args[2:])
+	case "encrypt":
+		err = encrypt(os.Args[2:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %s\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hocon: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func keygen(args []string) error {
+	flags := flag.NewFlagSet("keygen", flag.ExitOnError)
+	size := flags.Int("size", 32, "key size in bytes: 16, 24 or 32")
+	_ = flags.Parse(args)
+
+	if *size != 16 && *size != 24 && *size != 32 {
+		return fmt.Errorf("wrong key size %d, use 16, 24 or 32", *size)
+	}
+	key := make([]byte, *size)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("cannot generate key: %w", err)
+	}
+	fmt.Println(base64.StdEncoding.EncodeToString(key))
+	return nil
+}
+
+func encrypt(args []string) error {
+	flags := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	encodedKey := flags.String("key", "", "base64-encoded key, "+hocon.EncryptionKeyEnv+" is used if empty")
+	_ = flags.Parse(args)
+
+	if *encodedKey == "" {
+		*encodedKey = os.Getenv(hocon.EncryptionKeyEnv)
+	}
+	if *encodedKey == "" {
+		return errors.New("no encryption key, use -key flag or " + hocon.EncryptionKeyEnv + " variable")
+	}
+	key, err := hocon.ParseEncryptionKey(*encodedKey)
+	if err != nil {
+		return err
+	}
+
+	var text string
+	switch flags.NArg() {
+	case 0:
+		input, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("cannot read value: %w", err)
+		}
+		text = strings.TrimRight(string(input), "\r\n")
+	case 1:
+		text = flags.Arg(0)
+	default:
+		return errors.New("too many arguments, give a single value")
+	}
+
+	encrypted, err := hocon.Encrypt(text, key)
+	if err != nil {
+		return err
+	}
+	fmt.Println(encrypted)
+	return nil
+}
@@ -0,0 +1,119 @@
+package hocon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// Config is a parsed HOCON configuration with all the substitutions resolved. Config and *Config can be used
+// as field types of the struct to keep the section of the configuration located by the field path and to
+// decode it later.
+type Config struct {
+	root *objectValue
+}
+
+// ParseConfigFile parses HOCON file. Options like WithResolver are applied while substitutions are resolved.
+func ParseConfigFile(filename string, opts ...Option) (*Config, error) {
+	if err := checkFileAccessibility(filename); err != nil {
+		return nil, fmt.Errorf("cannot read configuration file: %w", err)
+	}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read configuration file: %w", err)
+	}
+	root, err := parseConfig(filename, string(content), newOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return &Config{root: root}, nil
+}
+
+// ParseConfigText parses given text as HOCON. Options like WithResolver are applied while substitutions are
+// resolved.
+func ParseConfigText(text string, opts ...Option) (*Config, error) {
+	root, err := parseConfig("", text, newOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return &Config{root: root}, nil
+}
+
+// ParseConfigDir parses all `*.conf` files of the directory, and `*.json` ones if WithJSONFiles option is given,
+// in lexical order of their names. Files are merged like duplicate keys of a single file: values of later files
+// override values of earlier ones, objects are merged, self-referential fields like `a += 2` extend values of
+// earlier files. Substitutions are resolved after merging, so they may refer to values from any file. Profile
+// sections are applied, but profile files are not looked for because all the files of the directory are loaded
+// anyway.
+func ParseConfigDir(dir string, opts ...Option) (*Config, error) {
+	options := newOptions(opts)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read configuration directory: %w", err)
+	}
+
+	root := newObject(Origin{Filename: dir})
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.Mode().IsRegular() || ext != ".conf" && !(ext == ".json" && options.jsonFiles) {
+			continue
+		}
+		filename := filepath.Join(dir, entry.Name())
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read configuration file: %w", err)
+		}
+		// self-referential fields of the file refer to the values of earlier files
+		fileRoot, err := parse(filename, string(content), newIncluder(options), nil, root)
+		if err != nil {
+			return nil, err
+		}
+		root = mergeValues(root, fileRoot).(*objectValue)
+	}
+
+	if root, err = finishConfig(root, "", options); err != nil {
+		return nil, err
+	}
+	return &Config{root: root}, nil
+}
+
+// WithJSONFiles makes ParseConfigDir and LoadConfigDir load `*.json` files of the directory too.
+func WithJSONFiles() Option {
+	return func(opts *options) {
+		opts.jsonFiles = true
+	}
+}
+
+// Decode loads configuration parameters to given structure.
+func (config *Config) Decode(receiver interface{}, opts ...Option) error {
+	options := newOptions(opts)
+	root := config.rootObject()
+	if err := loadConfig(root, receiver, options); err != nil {
+		return err
+	}
+	options.reportUnknown(root, "")
+	return nil
+}
+
+// Origin returns the origin of the value located by given path expression. It returns false if there is no
+// such value or the path expression is malformed.
+func (config *Config) Origin(path string) (Origin, bool) {
+	parsed, err := parsePath(path)
+	if err != nil {
+		return Origin{}, false
+	}
+	found := config.rootObject().find(parsed)
+	if found == nil {
+		return Origin{}, false
+	}
+	return found.pos(), true
+}
+
+// rootObject returns the root object of the configuration. Zero Config, like the one left in an absent optional
+// field, and nil *Config are empty configurations.
+func (config *Config) rootObject() *objectValue {
+	if config == nil || config.root == nil {
+		return newObject(Origin{})
+	}
+	return config.root
+}
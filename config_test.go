@@ -0,0 +1,182 @@
+package hocon
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigDecode(t *testing.T) {
+	config, err1 := ParseConfigFile("tests/conf1.conf")
+	if assert.Nil(t, err1) {
+		props1 := struct {
+			Key3 int32
+		}{}
+		if assert.Nil(t, config.Decode(&props1)) {
+			assert.Equal(t, int32(555), props1.Key3)
+		}
+	}
+
+	_, err2 := ParseConfigFile("tests")
+	assert.Error(t, err2)
+	_, err3 := ParseConfigText("{a: 1")
+	assert.Error(t, err3)
+}
+
+func TestConfigOrigin(t *testing.T) {
+	config, err1 := ParseConfigText("a {\n  b: 1\n  c: foo bar\n}\nd: ${a.b}")
+	if assert.Nil(t, err1) {
+		origin, found := config.Origin("a.b")
+		assert.True(t, found)
+		assert.Equal(t, Origin{Line: 2, Column: 6}, origin)
+
+		origin, found = config.Origin("a.c")
+		assert.True(t, found)
+		assert.Equal(t, Origin{Line: 3, Column: 6}, origin)
+
+		origin, found = config.Origin("d")
+		assert.True(t, found)
+		assert.Equal(t, Origin{Line: 2, Column: 6}, origin)
+
+		origin, found = config.Origin("a")
+		assert.True(t, found)
+		assert.Equal(t, Origin{Line: 1, Column: 3}, origin)
+
+		_, found = config.Origin("a.x")
+		assert.False(t, found)
+		_, found = config.Origin("a..b")
+		assert.False(t, found)
+	}
+}
+
+func TestConfigOriginFile(t *testing.T) {
+	config, err1 := ParseConfigFile("tests/conf1.conf")
+	if assert.Nil(t, err1) {
+		origin, found := config.Origin("container1.Key9")
+		assert.True(t, found)
+		assert.Equal(t, Origin{Filename: "tests/conf1.conf", Line: 10, Column: 11}, origin)
+		assert.Equal(t, "tests/conf1.conf:10:11", origin.String())
+	}
+}
+
+func TestConfigOriginEnv(t *testing.T) {
+	assert.Nil(t, os.Setenv("HOCON_ORIGIN_TEST", "1"))
+	defer func() {
+		_ = os.Unsetenv("HOCON_ORIGIN_TEST")
+	}()
+	config, err1 := ParseConfigText("a: ${HOCON_ORIGIN_TEST}")
+	if assert.Nil(t, err1) {
+		origin, found := config.Origin("a")
+		assert.True(t, found)
+		assert.Equal(t, Origin{Line: 1, Column: 4, Env: "HOCON_ORIGIN_TEST"}, origin)
+		assert.Equal(t, "env HOCON_ORIGIN_TEST at 1:4", origin.String())
+	}
+}
+
+func TestOriginString(t *testing.T) {
+	origin := Origin{Filename: "b.conf", Line: 2, Column: 3, IncludedFrom: &Origin{Filename: "a.conf", Line: 5,
+		Column: 1, IncludedFrom: &Origin{Filename: "root.conf", Line: 1, Column: 1}}}
+	assert.Equal(t, "b.conf:2:3, included from a.conf:5:1, included from root.conf:1:1", origin.String())
+}
+
+func TestLoadErrorOrigin(t *testing.T) {
+	props1 := struct {
+		Field1 int8
+	}{}
+	err1 := LoadConfigText("{\n  Field1: 128\n}", &props1)
+	if assert.Error(t, err1) {
+		assert.Regexp(t, "^wrong value for Field1 \\[\\] at 2:11: ", err1)
+	}
+
+	props2 := struct {
+		Field1 []int8
+	}{}
+	err2 := LoadConfigText("Field1: [1,\n  1000]", &props2)
+	if assert.Error(t, err2) {
+		assert.Regexp(t, "^wrong value for Field1 \\[\\] at 2:3: ", err2)
+	}
+
+	props3 := struct {
+		Field1 string
+	}{}
+	err3 := LoadConfigText("Field1: {a: 1}", &props3)
+	if assert.Error(t, err3) {
+		assert.Regexp(t, "^wrong value for Field1 \\[\\] at 1:9: cannot use object as string$", err3)
+	}
+}
+
+type pluginConfig struct {
+	Size int32
+	Mode string `hocon:"default=lru"`
+}
+
+type pluginHost struct {
+	Name    string
+	Plugin  Config  `hocon:"path=plugins.cache"`
+	Pointer *Config `hocon:"path=plugins.other"`
+}
+
+func TestConfigField(t *testing.T) {
+	var host pluginHost
+	err := LoadConfigText("Name: host, plugins { cache { Size: 10 }, other { Size: 20, Mode: fifo } }", &host)
+	if assert.Nil(t, err) && assert.NotNil(t, host.Pointer) {
+		assert.Equal(t, "host", host.Name)
+
+		var cache pluginConfig
+		if assert.Nil(t, host.Plugin.Decode(&cache)) {
+			assert.Equal(t, pluginConfig{Size: 10, Mode: "lru"}, cache)
+		}
+		var other pluginConfig
+		if assert.Nil(t, host.Pointer.Decode(&other)) {
+			assert.Equal(t, pluginConfig{Size: 20, Mode: "fifo"}, other)
+		}
+
+		origin, found := host.Plugin.Origin("Size")
+		assert.True(t, found)
+		assert.Equal(t, Origin{Line: 1, Column: 37}, origin)
+
+		// sections are masked as they are not decoded yet
+		assert.Equal(t, "Name = \"host\"\nplugins {\n  cache {\n    Size = \"******\"\n  }\n  other {\n"+
+			"    Size = \"******\"\n    Mode = \"******\"\n  }\n}\n", Dump(host))
+	}
+}
+
+func TestEmptyConfig(t *testing.T) {
+	var host struct {
+		Plugin  Config  `hocon:"optional"`
+		Pointer *Config `hocon:"optional"`
+	}
+	if assert.Nil(t, LoadConfigText("", &host)) {
+		var plugin struct {
+			Size int32 `hocon:"default=10"`
+		}
+		assert.Nil(t, host.Plugin.Decode(&plugin))
+		assert.Equal(t, int32(10), plugin.Size)
+		assert.Nil(t, host.Pointer.Decode(&plugin))
+
+		_, found := host.Plugin.Origin("Size")
+		assert.False(t, found)
+		_, found = host.Pointer.Origin("Size")
+		assert.False(t, found)
+
+		var required struct{ Size int32 }
+		assert.EqualError(t, host.Plugin.Decode(&required), "no value either default value provided for Size []")
+	}
+}
+
+func TestConfigFieldErrors(t *testing.T) {
+	var host pluginHost
+	err := LoadConfigText("Name: host, plugins { cache: 1, other {} }", &host)
+	assert.EqualError(t, err, "wrong value for Plugin [hocon:\"path=plugins.cache\"] at 1:30: cannot use number as config")
+
+	var withDefault struct {
+		A Config `hocon:"default=x"`
+	}
+	err = LoadConfigText("", &withDefault)
+	assert.EqualError(t, err, "configs do not support default value: A [hocon:\"default=x\"]")
+
+	var pointer struct{ A *int32 }
+	err = LoadConfigText("A: 1", &pointer)
+	assert.EqualError(t, err, "unimplemented data type *int32")
+}
@@ -0,0 +1,227 @@
+package hocon
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Conformance cases are modelled on the Lightbend config test suite. Every case names the section of
+// HOCON.md it covers, the list of covered sections is kept in README.
+
+type confString struct{ A string }
+
+type confStrings struct{ A []string }
+
+type confInt struct{ A int64 }
+
+type confFloat struct{ A float64 }
+
+type confDefault struct {
+	A string `hocon:"default=fallback"`
+}
+
+type confObject struct {
+	A struct {
+		X string `hocon:"default=none"`
+		Y string `hocon:"default=none"`
+	}
+}
+
+type confSpacedKey struct {
+	A string `hocon:"path=foo bar"`
+}
+
+type confQuotedPath struct {
+	A string `hocon:"path=\"a.b\""`
+	B string `hocon:"path=x.\"y.z\".w"`
+}
+
+type conformanceCase struct {
+	section  string
+	input    string
+	expected interface{}
+	err      string
+}
+
+var conformanceCases = []conformanceCase{
+	// JSON compatibility
+	{section: "Syntax", input: `{"A": "json"}`, expected: confString{"json"}},
+	{section: "Syntax", input: `{"A": ["a", "b"],}`, expected: confStrings{[]string{"a", "b"}}},
+	{section: "Omit root braces", input: "A = braceless", expected: confString{"braceless"}},
+	{section: "Key-value separator", input: "A : colon", expected: confString{"colon"}},
+	{section: "Key-value separator", input: "A { X = 1 }", expected: confObject{A: struct {
+		X string `hocon:"default=none"`
+		Y string `hocon:"default=none"`
+	}{X: "1", Y: "none"}}},
+
+	// comments and whitespace
+	{section: "Comments", input: "A = value # comment", expected: confString{"value"}},
+	{section: "Comments", input: "A = value// comment", expected: confString{"value"}},
+	{section: "Comments", input: `A = "// not a comment"`, expected: confString{"// not a comment"}},
+	{section: "Whitespace", input: "\ufeffA\u00a0=\u2003value\u00a0", expected: confString{"value"}},
+
+	// commas and newlines
+	{section: "Commas", input: "A = [\n  a\n  b,\n  c,\n]", expected: confStrings{[]string{"a", "b", "c"}}},
+	{section: "Commas", input: "A = [a,,b]", err: "expected array element"},
+
+	// strings
+	{section: "Quoted strings", input: `A = "é\t\"\\"`, expected: confString{"é\t\"\\"}},
+	{section: "Quoted strings", input: `A = "${B}", B = b`, expected: confString{"${B}"}},
+	{section: "Quoted strings", input: `A = "\u00e9 \ud83d\ude00"`, expected: confString{"é 😀"}},
+	{section: "Quoted strings", input: `A = "\ud83dx"`, expected: confString{"\ufffdx"}},
+	{section: "Unquoted strings", input: "A = 1.2.3", expected: confString{"1.2.3"}},
+	{section: "Unquoted strings", input: "A = foo+bar", err: "'\\+' is not allowed"},
+	{section: "Multi-line strings", input: `A = """foo"""`, expected: confString{"foo"}},
+	{section: "Multi-line strings", input: `A = """"foo""""`, expected: confString{`"foo"`}},
+	{section: "Multi-line strings", input: "A = \"\"\"\n  line 1\n  \"line\" \\n 2\n\"\"\"",
+		expected: confString{"\n  line 1\n  \"line\" \\n 2\n"}},
+
+	// value concatenation
+	{section: "Value concatenation", input: "A = foo bar   baz", expected: confString{"foo bar   baz"}},
+	{section: "Value concatenation", input: `A = foo"bar" "baz"`, expected: confString{"foobar baz"}},
+	{section: "Value concatenation", input: "A = 1 true null 1.0", expected: confString{"1 true null 1.0"}},
+	{section: "Value concatenation", input: "A = ${B} ms, B = 10", expected: confString{"10 ms"}},
+	{section: "Array and object concatenation", input: "A = [a] [b] ${B}, B = [c]",
+		expected: confStrings{[]string{"a", "b", "c"}}},
+	{section: "Array and object concatenation", input: "A = {X: 1} {Y: 2}", expected: confObject{A: struct {
+		X string `hocon:"default=none"`
+		Y string `hocon:"default=none"`
+	}{X: "1", Y: "2"}}},
+	{section: "Array and object concatenation", input: "A = [a] b", err: "cannot concatenate array with string"},
+	{section: "Array and object concatenation", input: "A = {X: 1} [a]", err: "cannot concatenate object with array"},
+
+	// numbers
+	{section: "Numbers", input: "A = -0.5e-3", expected: confFloat{-0.0005}},
+	{section: "Numbers", input: "A = 10 // comment", expected: confInt{10}},
+	{section: "Numbers", input: "A = ${B}, B = 1.0", expected: confString{"1.0"}},
+
+	// paths
+	{section: "Path expressions", input: `a.b = 1, "a.b" = 2, x."y.z".w = 3`, expected: confQuotedPath{"2", "3"}},
+	{section: "Path expressions", input: `foo bar = 1`, expected: confSpacedKey{"1"}},
+	{section: "Path expressions", input: `"foo" bar = 1`, expected: confSpacedKey{"1"}},
+	{section: "Path expressions", input: `A = ${"a.b"}, "a.b" = 1`, expected: confString{"1"}},
+	{section: "Path expressions", input: `"a"."b" = 1, "a.b" = 2, x { "y.z" { w: 3 } }`, expected: confQuotedPath{"2", "3"}},
+	{section: "Paths as keys", input: "A.X = 1, A { Y = 2 }", expected: confObject{A: struct {
+		X string `hocon:"default=none"`
+		Y string `hocon:"default=none"`
+	}{X: "1", Y: "2"}}},
+	{section: "Paths as keys", input: "A..X = 1", err: "path has an empty element"},
+
+	// duplicate keys
+	{section: "Duplicate keys and object merging", input: "A = first, A = second", expected: confString{"second"}},
+	{section: "Duplicate keys and object merging", input: "A { X = 1 }, A { Y = 2 }", expected: confObject{A: struct {
+		X string `hocon:"default=none"`
+		Y string `hocon:"default=none"`
+	}{X: "1", Y: "2"}}},
+	{section: "Duplicate keys and object merging", input: "A { X = 1 }, A = 5, A { Y = 2 }",
+		expected: confObject{A: struct {
+			X string `hocon:"default=none"`
+			Y string `hocon:"default=none"`
+		}{X: "none", Y: "2"}}},
+	{section: "Duplicate keys and object merging", input: "A { X = 1 }, A = null, A { Y = 2 }",
+		expected: confObject{A: struct {
+			X string `hocon:"default=none"`
+			Y string `hocon:"default=none"`
+		}{X: "none", Y: "2"}}},
+	{section: "Duplicate keys and object merging", input: "A = ${B}, A { Y = 2 }, B { X = 1 }",
+		expected: confObject{A: struct {
+			X string `hocon:"default=none"`
+			Y string `hocon:"default=none"`
+		}{X: "1", Y: "2"}}},
+
+	// substitutions
+	{section: "Substitutions", input: "A = ${B.C}, B { C = deep }", expected: confString{"deep"}},
+	{section: "Substitutions", input: "A = ${B}, B = ${C}, C = chained", expected: confString{"chained"}},
+	{section: "Substitutions", input: "A = ${B}", err: "1:5: could not resolve substitution \\${B}"},
+	{section: "Substitutions", input: "A = ${?B}", expected: confDefault{"fallback"}},
+	{section: "Substitutions", input: "A = first, A = ${?B}", expected: confString{"first"}},
+	{section: "Substitutions", input: "A = [a, ${?B}, c]", expected: confStrings{[]string{"a", "c"}}},
+	{section: "Substitutions", input: "A = ${?B}foo", expected: confString{"foo"}},
+	{section: "Substitutions", input: "B { C = 1, D = ${B.C} }, A = ${B.D}", expected: confString{"1"}},
+	{section: "Substitutions", input: "B { C = 1, D = ${B} }, A = ${B.C}", err: "cycle in substitutions"},
+	{section: "Substitutions", input: "A = null", expected: confDefault{"fallback"}},
+	{section: "Substitutions", input: "A = ${HOCON_CONFORMANCE}", expected: confString{"from env"}},
+	{section: "Substitutions", input: "A = ${B}, B = ${A}", err: "cycle in substitutions"},
+	{section: "Substitutions", input: "A = ${B}, B = [${A}]", err: "cycle in substitutions"},
+
+	// self-referential substitutions
+	{section: "Self-Referential Substitutions", input: "A = 1, A = ${A}", expected: confInt{1}},
+	{section: "Self-Referential Substitutions", input: `A = "a:b", A = ${A}":c"`, expected: confString{"a:b:c"}},
+	{section: "Self-Referential Substitutions", input: "A = [a], A = ${A} [b]", expected: confStrings{[]string{"a", "b"}}},
+	{section: "Self-Referential Substitutions", input: "A = ${?A} [b]", expected: confStrings{[]string{"b"}}},
+	{section: "Self-Referential Substitutions", input: "A { X = 1 }, A = ${A} { Y = 2 }", expected: confObject{A: struct {
+		X string `hocon:"default=none"`
+		Y string `hocon:"default=none"`
+	}{X: "1", Y: "2"}}},
+	{section: "Self-Referential Substitutions", input: "A { X = 1 }, A = ${A.X}", expected: confString{"1"}},
+	{section: "Self-Referential Substitutions", input: "A { X = a, X = ${A.X}b }", expected: confObject{A: struct {
+		X string `hocon:"default=none"`
+		Y string `hocon:"default=none"`
+	}{X: "ab", Y: "none"}}},
+	{section: "Self-Referential Substitutions", input: "A { X = 1 }, A = ${A.X}, A { Y = 2 }",
+		expected: confObject{A: struct {
+			X string `hocon:"default=none"`
+			Y string `hocon:"default=none"`
+		}{X: "none", Y: "2"}}},
+	{section: "Self-Referential Substitutions", input: "A { X = 1 }, A = ${B}, A { Y = 2 }, B = 10",
+		expected: confObject{A: struct {
+			X string `hocon:"default=none"`
+			Y string `hocon:"default=none"`
+		}{X: "none", Y: "2"}}},
+	{section: "Self-Referential Substitutions", input: "A.X = a, A.X = ${A.X}b", expected: confObject{A: struct {
+		X string `hocon:"default=none"`
+		Y string `hocon:"default=none"`
+	}{X: "ab", Y: "none"}}},
+	{section: "Self-Referential Substitutions", input: "A = first, A = ${?A}", expected: confString{"first"}},
+	{section: "Self-Referential Substitutions", input: "A = ${?A}", expected: confDefault{"fallback"}},
+	{section: "Self-Referential Substitutions", input: "A = ${B}, A = ${A} x, B = b", expected: confString{"b x"}},
+	{section: "Self-Referential Substitutions", input: "A = ${HOCON_CONFORMANCE}, A = ${A}\"!\"",
+		expected: confString{"from env!"}},
+	{section: "Self-Referential Substitutions", input: "A = ${A}", err: "could not resolve substitution \\${A}"},
+
+	// += field separator
+	{section: "The += field separator", input: "A = [a], A += b, A += ${B}, B = c",
+		expected: confStrings{[]string{"a", "b", "c"}}},
+	{section: "The += field separator", input: "A += a", expected: confStrings{[]string{"a"}}},
+	{section: "The += field separator", input: "x { y { A += a, A += b } }, A = ${x.y.A}",
+		expected: confStrings{[]string{"a", "b"}}},
+
+	// includes
+	{section: "Includes", input: "include \"tests/nonexistent\"\nA = a", expected: confString{"a"}},
+	{section: "Includes", input: "A { include \"tests/nonexistent\" }", err: "cannot use object as string"},
+	{section: "Includes", input: "include required(\"tests/nonexistent\")", err: "required configuration does not exist"},
+	{section: "Includes", input: "include = a, A = ${include}", expected: confString{"a"}},
+	{section: "Includes", input: "include classpath(\"a.conf\")", err: "classpath includes are not supported"},
+}
+
+func TestConformance(t *testing.T) {
+	assert.Nil(t, os.Setenv("HOCON_CONFORMANCE", "from env"))
+	defer func() {
+		_ = os.Unsetenv("HOCON_CONFORMANCE")
+	}()
+
+	for _, c := range conformanceCases {
+		t.Run(c.section, func(t *testing.T) {
+			var receiver reflect.Value
+			if c.expected != nil {
+				receiver = reflect.New(reflect.TypeOf(c.expected))
+			} else {
+				receiver = reflect.New(reflect.TypeOf(confString{}))
+			}
+
+			err := LoadConfigText(c.input, receiver.Interface())
+			if c.err != "" {
+				if assert.Error(t, err, c.input) {
+					assert.Regexp(t, c.err, err, c.input)
+				}
+				return
+			}
+			if assert.Nil(t, err, c.input) {
+				assert.Equal(t, c.expected, receiver.Elem().Interface(), c.input)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package hocon
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EncryptionKeyEnv is the environment variable holding base64-encoded key to decrypt ENC(...) values with,
+// it is used if the key is not given by WithEncryptionKey option.
+const EncryptionKeyEnv = "HOCON_ENCRYPTION_KEY"
+
+const (
+	encryptedPrefix = "ENC("
+	encryptedSuffix = ")"
+)
+
+// Encrypt encrypts the text with AES-GCM and returns the value like `ENC(...)` which is decrypted while loading
+// the configuration. Key must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256. Encrypted data
+// is encoded with URL-safe base64 without padding, so the value can be used in HOCON without quotes.
+func Encrypt(text string, key []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("cannot generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(text), nil)
+	return encryptedPrefix + base64.RawURLEncoding.EncodeToString(sealed) + encryptedSuffix, nil
+}
+
+// ParseEncryptionKey decodes base64-encoded key as it is kept in HOCON_ENCRYPTION_KEY variable.
+func ParseEncryptionKey(encoded string) ([]byte, error) {
+	key, err := decodeBase64(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("malformed encryption key: %w", err)
+	}
+	return key, nil
+}
+
+// WithEncryptionKey sets the key to decrypt ENC(...) values with. Without the option the key is read from
+// HOCON_ENCRYPTION_KEY environment variable.
+func WithEncryptionKey(key []byte) Option {
+	return func(opts *options) {
+		opts.encryptionKey = key
+	}
+}
+
+// isEncrypted tells if the text looks like an encrypted value.
+func isEncrypted(text string) bool {
+	return strings.HasPrefix(text, encryptedPrefix) && strings.HasSuffix(text, encryptedSuffix)
+}
+
+// decrypt decrypts the value like `ENC(...)` made by Encrypt.
+func decrypt(text string, key []byte) (string, error) {
+	data, err := decodeBase64(text[len(encryptedPrefix) : len(text)-len(encryptedSuffix)])
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("malformed encrypted value: too short")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt value: %w", err)
+	}
+	return string(plain), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("wrong encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// decodeBase64 accepts both standard and URL-safe base64 with or without padding.
+func decodeBase64(encoded string) ([]byte, error) {
+	encoded = strings.TrimRight(encoded, "=")
+	if strings.ContainsAny(encoded, "+/") {
+		return base64.RawStdEncoding.DecodeString(encoded)
+	}
+	return base64.RawURLEncoding.DecodeString(encoded)
+}
+
+// decryptValue replaces encrypted strings in the value, in the elements of the array and in the fields of the
+// object with decrypted ones. The key is looked up only if there is something to decrypt.
+func (opts *options) decryptValue(hoconValue value) (value, error) {
+	switch typed := hoconValue.(type) {
+	case *stringValue:
+		if !isEncrypted(typed.text) {
+			return typed, nil
+		}
+		key, err := opts.key()
+		if err != nil {
+			return nil, err
+		}
+		plain, err := decrypt(typed.text, key)
+		if err != nil {
+			return nil, err
+		}
+		return &stringValue{Origin: typed.Origin, text: plain}, nil
+
+	case *arrayValue:
+		array := &arrayValue{Origin: typed.Origin}
+		for _, element := range typed.elements {
+			decrypted, err := opts.decryptValue(element)
+			if err != nil {
+				return nil, err
+			}
+			array.elements = append(array.elements, decrypted)
+		}
+		return array, nil
+
+	case *objectValue:
+		obj := newObject(typed.Origin)
+		for _, key := range typed.keys {
+			decrypted, err := opts.decryptValue(typed.fields[key])
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key, decrypted)
+		}
+		return obj, nil
+	}
+	return hoconValue, nil
+}
+
+// key returns the encryption key given by the option or by the environment variable.
+func (opts *options) key() ([]byte, error) {
+	if opts.encryptionKey != nil {
+		return opts.encryptionKey, nil
+	}
+	encoded, ok := os.LookupEnv(EncryptionKeyEnv)
+	if !ok {
+		return nil, fmt.Errorf("no encryption key to decrypt value, use WithEncryptionKey option or %s variable",
+			EncryptionKeyEnv)
+	}
+	return ParseEncryptionKey(encoded)
+}
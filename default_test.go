@@ -0,0 +1,91 @@
+package hocon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type defaultProperties struct {
+	Ports   []int32                `hocon:"default=[80, 443]"`
+	Hosts   []string               `hocon:"node=hosts,default=[\"a,b\", c]"`
+	Options map[string]interface{} `hocon:"default={a=1, b: [x, y]}"`
+	Server  struct {
+		Host string
+		Port int32 `hocon:"default=8080"`
+	} `hocon:"default={Host: localhost}"`
+}
+
+func TestLiteralDefaults(t *testing.T) {
+	var props defaultProperties
+	var report Report
+	err := LoadConfigText("", &props, WithReport(&report))
+	if assert.Nil(t, err) {
+		assert.Equal(t, []int32{80, 443}, props.Ports)
+		assert.Equal(t, []string{"a,b", "c"}, props.Hosts)
+		assert.Equal(t, map[string]interface{}{"a": int64(1), "b": []interface{}{"x", "y"}}, props.Options)
+		assert.Equal(t, "localhost", props.Server.Host)
+		assert.Equal(t, int32(8080), props.Server.Port)
+		assert.Equal(t, []ReportEntry{
+			{Field: "Ports", Path: "Ports", Source: SourceDefault},
+			{Field: "Hosts", Path: "hosts", Source: SourceDefault},
+			{Field: "Options", Path: "Options", Source: SourceDefault},
+			{Field: "Server", Path: "Server", Source: SourceDefault},
+		}, report.Entries)
+	}
+
+	props = defaultProperties{}
+	err = LoadConfigText("Ports: [1], hosts: [h], Options { c: 3 }, Server { Host: example.com, Port: 1 }", &props)
+	if assert.Nil(t, err) {
+		assert.Equal(t, []int32{1}, props.Ports)
+		assert.Equal(t, []string{"h"}, props.Hosts)
+		assert.Equal(t, map[string]interface{}{"c": int64(3)}, props.Options)
+		assert.Equal(t, "example.com", props.Server.Host)
+		assert.Equal(t, int32(1), props.Server.Port)
+	}
+
+	var generic struct {
+		List interface{} `hocon:"default=[1, 2]"`
+		Name interface{} `hocon:"default=app"`
+	}
+	err = LoadConfigText("", &generic)
+	if assert.Nil(t, err) {
+		assert.Equal(t, []interface{}{int64(1), int64(2)}, generic.List)
+		assert.Equal(t, "app", generic.Name)
+	}
+}
+
+func TestLiteralDefaultErrors(t *testing.T) {
+	var wrongList struct {
+		A []int32 `hocon:"default=[1, x]"`
+	}
+	err := LoadConfigText("A: [1]", &wrongList)
+	assert.EqualError(t, err, "wrong default value for A [hocon:\"default=[1, x]\"]: "+
+		"strconv.ParseInt: parsing \"x\": invalid syntax")
+
+	var notList struct {
+		A []int32 `hocon:"default=1"`
+	}
+	err = LoadConfigText("", &notList)
+	assert.EqualError(t, err, "wrong default value for A [hocon:\"default=1\"]: cannot use number as list")
+
+	var notStruct struct {
+		A struct{ B string } `hocon:"default=[]"`
+	}
+	err = LoadConfigText("", &notStruct)
+	assert.EqualError(t, err, "wrong default value for A [hocon:\"default=[]\"]: cannot use array as struct")
+
+	var incomplete struct {
+		A struct{ B, C string } `hocon:"default={B: b}"`
+	}
+	err = LoadConfigText("", &incomplete)
+	assert.EqualError(t, err, "wrong default value for A [hocon:\"default={B: b}\"]: "+
+		"no value either default value provided for C []")
+
+	var substituted struct {
+		A []string `hocon:"default=[${HOME}]"`
+	}
+	err = LoadConfigText("", &substituted)
+	assert.EqualError(t, err, "wrong default value for A [hocon:\"default=[${HOME}]\"]: "+
+		"substitutions are not supported in default values")
+}
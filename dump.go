@@ -0,0 +1,298 @@
+package hocon
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// Dump renders the loaded struct as HOCON text using the same paths the loader uses, give it the options which
+// change paths like WithNaming. Values of fields tagged with `secret=true`, values of Secret type and values of
+// Config sections are masked.
+func Dump(v interface{}, opts ...Option) string {
+	root := newObject(Origin{})
+	for _, leaf := range dumpLeaves(v, newOptions(opts)) {
+		root.setPath(leaf.path, leaf.value)
+	}
+	var sb strings.Builder
+	writeObject(&sb, root, "")
+	return sb.String()
+}
+
+// Redacted renders the loaded struct as a flat `path = value` listing, one line per value, maps and Config
+// sections are flattened down to their values. Values are masked the same way Dump masks them.
+func Redacted(v interface{}, opts ...Option) string {
+	var sb strings.Builder
+	for _, leaf := range dumpLeaves(v, newOptions(opts)) {
+		writeFlat(&sb, leaf.path, leaf.value)
+	}
+	return sb.String()
+}
+
+// dumpLeaf is a single loaded field of a struct converted to HOCON value.
+type dumpLeaf struct {
+	path  []string
+	value value
+}
+
+func dumpLeaves(v interface{}, opts *options) []dumpLeaf {
+	structValue := reflect.Indirect(reflect.ValueOf(v))
+	if structValue.Kind() != reflect.Struct {
+		return nil
+	}
+	return dumpStruct("", structValue, nil, opts)
+}
+
+// dumpStruct walks through struct fields the same way loadStruct does and collects their values.
+func dumpStruct(parentPath string, structValue reflect.Value, leaves []dumpLeaf, opts *options) []dumpLeaf {
+	typ := structValue.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if isIgnored(&field) {
+			continue
+		}
+		currentPath, err := getPath(parentPath, &field, opts)
+		if err != nil {
+			currentPath = field.Name
+		}
+
+		if _, embedded := embeddedStruct(&field); embedded {
+			embeddedValue := structValue.Field(i)
+			if embeddedValue.Kind() == reflect.Ptr {
+				if embeddedValue.IsNil() {
+					continue
+				}
+				embeddedValue = embeddedValue.Elem()
+			}
+			if isFlattened(&field) {
+				currentPath = parentPath
+			}
+			leaves = redactLeaves(leaves, dumpStruct(currentPath, embeddedValue, leaves, opts), &field)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct && field.Type != configType {
+			leaves = redactLeaves(leaves, dumpStruct(currentPath, structValue.Field(i), leaves, opts), &field)
+			continue
+		}
+
+		path, err := parsePath(currentPath)
+		if err != nil {
+			path = []string{currentPath}
+		}
+		var fieldValue value
+		if isSecretField(&field) {
+			fieldValue = redactValue(goToValue(structValue.Field(i), opts))
+		} else if isBytesType(field.Type) {
+			// malformed tags are rendered with the default encoding
+			tagMap, _ := mapTag(field.Tag)
+			fieldValue = &stringValue{text: encodeBytes(structValue.Field(i), bytesEncoding(tagMap))}
+		} else {
+			fieldValue = goToValue(structValue.Field(i), opts)
+		}
+		leaves = append(leaves, dumpLeaf{path: path, value: fieldValue})
+	}
+	return leaves
+}
+
+// redactLeaves masks the leaves of the struct field added to the leaves if the field is tagged as secret, so
+// no value of a secret struct is revealed.
+func redactLeaves(leaves []dumpLeaf, withField []dumpLeaf, field *reflect.StructField) []dumpLeaf {
+	if !isSecretField(field) {
+		return withField
+	}
+	for i := len(leaves); i < len(withField); i++ {
+		withField[i].value = redactValue(withField[i].value)
+	}
+	return withField
+}
+
+// redactValue masks all the scalar values of the tree keeping keys of objects and lengths of arrays, so
+// secret maps and lists can be loaded back.
+func redactValue(v value) value {
+	switch typed := v.(type) {
+	case *objectValue:
+		obj := newObject(Origin{})
+		for _, key := range typed.keys {
+			obj.set(key, redactValue(typed.fields[key]))
+		}
+		return obj
+	case *arrayValue:
+		array := &arrayValue{}
+		for _, element := range typed.elements {
+			array.elements = append(array.elements, redactValue(element))
+		}
+		return array
+	}
+	return &stringValue{text: redactedText}
+}
+
+// isSecretField tells if the field is tagged as secret. Fields with malformed tags are treated as secret
+// to never reveal what might be a secret.
+func isSecretField(field *reflect.StructField) bool {
+	tagMap, err := mapTag(field.Tag)
+	if err != nil {
+		return true
+	}
+	rawSecret, exists := tagMap[secretKey]
+	if !exists {
+		return false
+	}
+	secret, err := parseBool(rawSecret)
+	return secret || err != nil
+}
+
+// goToValue converts Go value to HOCON value tree.
+func goToValue(v reflect.Value, opts *options) value {
+	if v.Type() == secretType {
+		return &stringValue{text: redactedText}
+	}
+	if v.Type() == configType {
+		// sections are not decoded yet, so nobody knows which of their values are secrets
+		if root := v.Interface().(Config).root; root != nil {
+			return redactValue(root)
+		}
+		return newObject(Origin{})
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return &stringValue{text: v.String()}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &numberValue{text: strconv.FormatInt(v.Int(), 10)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &numberValue{text: strconv.FormatUint(v.Uint(), 10)}
+	case reflect.Float32:
+		return &numberValue{text: strconv.FormatFloat(v.Float(), 'g', -1, 32)}
+	case reflect.Float64:
+		return &numberValue{text: strconv.FormatFloat(v.Float(), 'g', -1, 64)}
+	case reflect.Bool:
+		return &booleanValue{value: v.Bool()}
+
+	case reflect.Slice, reflect.Array:
+		array := &arrayValue{}
+		for i := 0; i < v.Len(); i++ {
+			array.elements = append(array.elements, goToValue(v.Index(i), opts))
+		}
+		return array
+
+	case reflect.Map:
+		obj := newObject(Origin{})
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		for _, key := range keys {
+			obj.set(key.String(), goToValue(v.MapIndex(key), opts))
+		}
+		return obj
+
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return &nullValue{}
+		}
+		return goToValue(v.Elem(), opts)
+
+	case reflect.Struct:
+		obj := newObject(Origin{})
+		for _, leaf := range dumpStruct("", v, nil, opts) {
+			obj.setPath(leaf.path, leaf.value)
+		}
+		return obj
+	}
+	return &nullValue{}
+}
+
+// setPath puts the value to the object by path creating intermediate objects.
+func (obj *objectValue) setPath(path []string, v value) {
+	current := obj
+	for _, key := range path[:len(path)-1] {
+		next, ok := current.fields[key].(*objectValue)
+		if !ok {
+			next = newObject(Origin{})
+			current.set(key, next)
+		}
+		current = next
+	}
+	current.set(path[len(path)-1], v)
+}
+
+// writeObject writes object fields as HOCON, one field per line with given indent.
+func writeObject(sb *strings.Builder, obj *objectValue, indent string) {
+	for _, key := range obj.keys {
+		sb.WriteString(indent)
+		sb.WriteString(renderPath([]string{key}))
+		if nested, ok := obj.fields[key].(*objectValue); ok {
+			sb.WriteString(" {\n")
+			writeObject(sb, nested, indent+"  ")
+			sb.WriteString(indent)
+			sb.WriteString("}\n")
+			continue
+		}
+		sb.WriteString(" = ")
+		sb.WriteString(renderInline(obj.fields[key], indent))
+		sb.WriteByte('\n')
+	}
+}
+
+// renderInline renders a value which is written after a key or inside an array.
+func renderInline(v value, indent string) string {
+	switch typed := v.(type) {
+	case *stringValue:
+		return quoteString(typed.text)
+	case *objectValue:
+		var sb strings.Builder
+		sb.WriteString("{\n")
+		writeObject(&sb, typed, indent+"  ")
+		sb.WriteString(indent)
+		sb.WriteString("}")
+		return sb.String()
+	case *arrayValue:
+		elements := make([]string, len(typed.elements))
+		for i, element := range typed.elements {
+			elements[i] = renderInline(element, indent)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	}
+	s, _ := text(v)
+	return s
+}
+
+// writeFlat writes the value as `path = value` lines, objects are flattened down to their values.
+func writeFlat(sb *strings.Builder, path []string, v value) {
+	if obj, ok := v.(*objectValue); ok && len(obj.keys) > 0 {
+		for _, key := range obj.keys {
+			writeFlat(sb, append(append([]string{}, path...), key), obj.fields[key])
+		}
+		return
+	}
+	sb.WriteString(renderPath(path))
+	sb.WriteString(" = ")
+	sb.WriteString(renderFlat(v))
+	sb.WriteByte('\n')
+}
+
+// renderFlat renders the value on a single line, objects inside arrays are written like `{a = 1, b = 2}`.
+func renderFlat(v value) string {
+	switch typed := v.(type) {
+	case *objectValue:
+		fields := make([]string, len(typed.keys))
+		for i, key := range typed.keys {
+			fields[i] = renderPath([]string{key}) + " = " + renderFlat(typed.fields[key])
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case *arrayValue:
+		elements := make([]string, len(typed.elements))
+		for i, element := range typed.elements {
+			elements[i] = renderFlat(element)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	}
+	return renderInline(v, "")
+}
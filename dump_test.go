@@ -0,0 +1,128 @@
+package hocon
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dumpProps struct {
+	Greeting string
+	Password Secret `hocon:"path=db.password"`
+	Token    string `hocon:"path=db.token,secret=true"`
+	DB       struct {
+		Host  string
+		Ports []int32
+	} `hocon:"node=db"`
+	Ratio   float32 `hocon:"path=numbers.ratio"`
+	Enabled bool    `hocon:"path=\"feature.flag\""`
+}
+
+func loadDumpProps(t *testing.T) dumpProps {
+	var props dumpProps
+	err := LoadConfigText(`
+		Greeting: hello "world"
+		db { password: hunter2, token: abcdef, Host: localhost, Ports: [5432, 5433] }
+		numbers.ratio: 1.5
+		"feature.flag": yes`, &props)
+	assert.Nil(t, err)
+	return props
+}
+
+func TestDump(t *testing.T) {
+	props := loadDumpProps(t)
+	assert.Equal(t, ""+
+		"Greeting = \"hello world\"\n"+
+		"db {\n"+
+		"  password = \"******\"\n"+
+		"  token = \"******\"\n"+
+		"  Host = \"localhost\"\n"+
+		"  Ports = [5432, 5433]\n"+
+		"}\n"+
+		"numbers {\n"+
+		"  ratio = 1.5\n"+
+		"}\n"+
+		"\"feature.flag\" = true\n", Dump(&props))
+
+	var reloaded dumpProps
+	assert.Nil(t, LoadConfigText(Dump(props), &reloaded))
+	assert.Equal(t, Secret(redactedText), reloaded.Password)
+	assert.Equal(t, props.DB, reloaded.DB)
+}
+
+func TestRedacted(t *testing.T) {
+	props := loadDumpProps(t)
+	assert.Equal(t, ""+
+		"Greeting = \"hello world\"\n"+
+		"db.password = \"******\"\n"+
+		"db.token = \"******\"\n"+
+		"db.Host = \"localhost\"\n"+
+		"db.Ports = [5432, 5433]\n"+
+		"numbers.ratio = 1.5\n"+
+		"\"feature.flag\" = true\n", Redacted(props))
+	assert.Equal(t, "", Redacted(42))
+}
+
+func TestSecret(t *testing.T) {
+	props := loadDumpProps(t)
+	assert.Equal(t, "hunter2", props.Password.Value())
+	assert.Equal(t, "abcdef", props.Token)
+
+	formatted := fmt.Sprintf("%v %s %q %#v %x %10s", props.Password, props.Password, props.Password,
+		props.Password, props.Password, props.Password)
+	assert.Equal(t, "****** ****** ****** ****** ****** ******", formatted)
+	assert.NotContains(t, fmt.Sprintf("%+v %#v", props, props), "hunter2")
+}
+
+type secretNested struct {
+	DB struct {
+		User     string
+		Password string
+		Options  map[string]interface{}
+	} `hocon:"secret"`
+	Embedded `hocon:"secret"`
+	Plugins  map[string]interface{} `hocon:"secret"`
+	Any      interface{}
+	Section  Config
+	Options  map[string]interface{}
+}
+
+type Embedded struct {
+	Key string
+}
+
+func TestDumpSecretStructs(t *testing.T) {
+	var props secretNested
+	props.DB.User = "admin"
+	props.DB.Password = "hunter2"
+	props.DB.Options = map[string]interface{}{"ssl": "0pts"}
+	props.Key = "k3y"
+	props.Plugins = map[string]interface{}{"auth": map[string]interface{}{"token": "t0ken"}}
+	props.Any = &secretNested{Plugins: map[string]interface{}{"a": "hidden"}}
+	section, err := ParseConfigText("auth { token: s3ction }, hosts: [{ name: h0st }]")
+	if assert.Nil(t, err) {
+		props.Section = *section
+	}
+	props.Options = map[string]interface{}{"cache": map[string]interface{}{"size": 10},
+		"list": []interface{}{map[string]interface{}{"a": 1}}}
+
+	dumped := Dump(props)
+	redacted := Redacted(props)
+	for _, secret := range []string{"admin", "hunter2", "k3y", "t0ken", "hidden", "0pts", "s3ction", "h0st"} {
+		assert.NotContains(t, dumped, secret)
+		assert.NotContains(t, redacted, secret)
+	}
+	assert.Contains(t, redacted, "DB.Password = \"******\"\n")
+	assert.Contains(t, redacted, "Key = \"******\"\n")
+	assert.Contains(t, redacted, "Plugins.auth.token = \"******\"\n")
+	assert.Contains(t, redacted, "Section.auth.token = \"******\"\n")
+	assert.Contains(t, redacted, "Section.hosts = [{name = \"******\"}]\n")
+	assert.Contains(t, redacted, "Options.cache.size = 10\n")
+	assert.Contains(t, redacted, "Options.list = [{a = 1}]\n")
+
+	var reloaded secretNested
+	if assert.Nil(t, LoadConfigText(dumped, &reloaded)) {
+		assert.Equal(t, redactedText, reloaded.DB.Password)
+	}
+}
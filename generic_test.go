@@ -0,0 +1,73 @@
+package hocon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type genericProperties struct {
+	Plugin   map[string]interface{}
+	Any      interface{}
+	Scalar   interface{}
+	List     []interface{}
+	Maps     []map[string]interface{}
+	Fallback interface{} `hocon:"default=none"`
+}
+
+func TestLoadGeneric(t *testing.T) {
+	var props genericProperties
+	err := LoadConfigText(`
+Plugin {
+  name = cache
+  size = 10
+  ratio = 0.5
+  enabled = true
+  nothing = null
+  tags = [a, 1]
+  nested { ttl = 10s }
+}
+Any = [1, {x = y}]
+Scalar = 9223372036854775808
+List = [true, "2"]
+Maps = [{a = 1}, {}]
+`, &props)
+	if assert.Nil(t, err) {
+		assert.Equal(t, genericProperties{
+			Plugin: map[string]interface{}{
+				"name":    "cache",
+				"size":    int64(10),
+				"ratio":   0.5,
+				"enabled": true,
+				"nothing": nil,
+				"tags":    []interface{}{"a", int64(1)},
+				"nested":  map[string]interface{}{"ttl": "10s"},
+			},
+			Any:      []interface{}{int64(1), map[string]interface{}{"x": "y"}},
+			Scalar:   9223372036854775808.0,
+			List:     []interface{}{true, "2"},
+			Maps:     []map[string]interface{}{{"a": int64(1)}, {}},
+			Fallback: "none",
+		}, props)
+	}
+}
+
+func TestLoadGenericErrors(t *testing.T) {
+	var receiver struct{ A map[string]interface{} }
+	err := LoadConfigText("A = [1]", &receiver)
+	assert.EqualError(t, err, "wrong value for A [] at 1:5: cannot use array as map")
+
+	var withDefault struct {
+		A map[string]interface{} `hocon:"default=x"`
+	}
+	err = LoadConfigText("", &withDefault)
+	assert.EqualError(t, err, "wrong default value for A [hocon:\"default=x\"]: cannot use string as map")
+
+	var typed struct{ A map[string]string }
+	err = LoadConfigText("A { x = y }", &typed)
+	assert.EqualError(t, err, "unimplemented data type map[string]string")
+
+	var list struct{ A []map[string]interface{} }
+	err = LoadConfigText("A = [1]", &list)
+	assert.EqualError(t, err, "wrong value for A [] at 1:6: cannot use number as map")
+}
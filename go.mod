@@ -1,8 +1,5 @@
 module github.com/artemkaxboy/go-hocon
 
-go 1.13
+go 1.16
 
-require (
-	github.com/artemkaxboy/configuration v0.0.0-20200109034048-a3def9c7c257
-	github.com/stretchr/testify v1.4.0
-)
+require github.com/stretchr/testify v1.4.0
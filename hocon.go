@@ -2,42 +2,66 @@ package hocon
 
 import (
 	"fmt"
-	"github.com/artemkaxboy/configuration"
-	"github.com/artemkaxboy/configuration/hocon"
+	"io/ioutil"
 	"os"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 const pathKey = "path"
 const nodeKey = "node"
 const defaultKey = "default"
+const secretKey = "secret"
+const fromFileKey = "fromfile"
+const optionalKey = "optional"
+const aliasKey = "alias"
+const deprecatedKey = "deprecated"
+const sepKey = "sep"
+const encodingKey = "encoding"
 
-var (
-	int64type = reflect.TypeOf(int64(0))
+// defaultSep separates elements of a string given to a slice field.
+const defaultSep = ","
+
+// ignoredTag makes the loader skip the field.
+const ignoredTag = "-"
 
-	tagKeys = map[string]interface{}{pathKey: nil, nodeKey: nil, defaultKey: nil}
+var (
+	int64type      = reflect.TypeOf(int64(0))
+	interfaceType  = reflect.TypeOf((*interface{})(nil)).Elem()
+	genericMapType = reflect.TypeOf(map[string]interface{}(nil))
+	configType     = reflect.TypeOf(Config{})
 )
 
+// fieldWrapper describes a struct to load. Inner is the field which holds the struct, it is nil for the root
+// struct and for flattened embedded ones which share the path of their parent. Single is the type of the struct
+// if it differs from the type of the field, like for embedded pointers. Path is the path the struct is found by
+// if it is already known, like an alias of the field.
 type fieldWrapper struct {
 	inner  *reflect.StructField
 	single reflect.Type
+	name   string
+	path   string
 }
 
 func (ptr *fieldWrapper) getType() reflect.Type {
-	if ptr.inner != nil {
-		return ptr.inner.Type
+	if ptr.single != nil {
+		return ptr.single
 	}
-	return ptr.single
+	return ptr.inner.Type
 }
 
-// getPath is a facade method to call getPath with inner (StructField) or return empty string
+// getPath is a facade method to call getPath with inner (StructField) or return parent path
 // if there is no inner element.
-func (ptr *fieldWrapper) getPath(parentPath string) (string, error) {
+func (ptr *fieldWrapper) getPath(parentPath string, opts *options) (string, error) {
 	if ptr.inner == nil {
-		return "", nil
+		return parentPath, nil
 	}
-	return getPath(parentPath, ptr.inner)
+	if ptr.path != "" {
+		return ptr.path, nil
+	}
+	return getPath(parentPath, ptr.inner, opts)
 }
 
 // getPath returns HOCON path for current element.
@@ -48,8 +72,9 @@ func (ptr *fieldWrapper) getPath(parentPath string) (string, error) {
 //
 // 2. Set node value in struct tag, then it will be added to the parent path with '.' delimiter
 //
-// 3. Do not set any tag, then the name of struct field (as is) will be added to the parent path with '.' delimiter
-func getPath(parentPath string, field *reflect.StructField) (string, error) {
+// 3. Do not set any tag, then the name of struct field converted by the naming strategy (as is by default) will be
+// added to the parent path with '.' delimiter
+func getPath(parentPath string, field *reflect.StructField, opts *options) (string, error) {
 	tagMap, err := mapTag(field.Tag)
 	if err != nil {
 		return "", err
@@ -67,62 +92,130 @@ func getPath(parentPath string, field *reflect.StructField) (string, error) {
 		return parentPath + node, nil
 	}
 
-	return parentPath + field.Name, nil
+	return parentPath + opts.naming(field.Name), nil
 }
 
 // LoadConfigFile loads HOCON files parameters to given structure.
-func LoadConfigFile(filename string, receiver interface{}) error {
+func LoadConfigFile(filename string, receiver interface{}, opts ...Option) error {
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Println("Cannot parse config: Panic")
+			logPanic(r, opts)
 			panic(r)
 		}
 	}()
-	if err := checkFileAccessibility(filename); err != nil {
-		return fmt.Errorf("cannot read configuration file: %w", err)
+	config, err := ParseConfigFile(filename, opts...)
+	if err != nil {
+		return err
+	}
+	return config.Decode(receiver, opts...)
+}
+
+// LoadConfigDir loads HOCON files of the directory to given structure, see ParseConfigDir for the details.
+func LoadConfigDir(dir string, receiver interface{}, opts ...Option) error {
+	defer func() {
+		if r := recover(); r != nil {
+			logPanic(r, opts)
+			panic(r)
+		}
+	}()
+	config, err := ParseConfigDir(dir, opts...)
+	if err != nil {
+		return err
 	}
-	config := configuration.LoadConfig(filename)
-	return loadConfig(config, receiver)
+	return config.Decode(receiver, opts...)
 }
 
 // LoadConfigText parses given text as HOCON and loads parameters to given structure.
-func LoadConfigText(text string, receiver interface{}) error {
+func LoadConfigText(text string, receiver interface{}, opts ...Option) error {
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Println("Cannot parse config: Panic")
+			logPanic(r, opts)
 			panic(r)
 		}
 	}()
-	config := configuration.ParseString(text)
-	return loadConfig(config, receiver)
+	config, err := ParseConfigText(text, opts...)
+	if err != nil {
+		return err
+	}
+	return config.Decode(receiver, opts...)
 }
 
 // loadConfig - is an entrypoint to a recursive function which walk through receiver structure to
 // find and load needed parameters.
-func loadConfig(config *configuration.Config, receiver interface{}) error {
+func loadConfig(config *objectValue, receiver interface{}, opts *options) error {
 	wrapper := &fieldWrapper{
 		single: reflect.ValueOf(receiver).Elem().Type(),
 	}
-	return loadStruct("", wrapper, reflect.ValueOf(receiver), config)
+	return loadStruct("", wrapper, reflect.ValueOf(receiver), config, opts)
 }
 
 // loadStruct recursively walk through receiver struct nested elements to fill them with the
 // config data.
-func loadStruct(parentPath string, field *fieldWrapper, fieldValue reflect.Value, config *configuration.Config) error {
-	currentPath, err2 := field.getPath(parentPath)
+func loadStruct(parentPath string, field *fieldWrapper, fieldValue reflect.Value, config *objectValue,
+	opts *options) error {
+	currentPath, err2 := field.getPath(parentPath, opts)
 	if err2 != nil {
 		return err2
 	}
 
 	for i := 0; i < field.getType().NumField(); i++ {
 		innerField := field.getType().Field(i)
-		if innerField.Type.Kind() == reflect.Struct {
-			wrapper := &fieldWrapper{inner: &innerField}
-			if err := loadStruct(currentPath, wrapper, fieldValue.Elem().FieldByName(innerField.Name).Addr(), config); err != nil {
+		innerValue := fieldValue.Elem().Field(i)
+		if isIgnored(&innerField) {
+			continue
+		}
+
+		if structType, embedded := embeddedStruct(&innerField); embedded {
+			if innerField.Type.Kind() == reflect.Ptr {
+				if innerField.PkgPath != "" {
+					// nil pointer of unexported type cannot be allocated
+					continue
+				}
+				if innerValue.IsNil() {
+					innerValue.Set(reflect.New(structType))
+				}
+				innerValue = innerValue.Elem()
+			}
+			wrapper := &fieldWrapper{single: structType, name: field.name}
+			if !isFlattened(&innerField) {
+				wrapper.inner = &innerField
+				wrapper.name = fieldName(field.name, &innerField)
+			}
+			if err := loadStruct(currentPath, wrapper, innerValue.Addr(), config, opts); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if innerField.PkgPath != "" {
+			continue
+		}
+		if innerField.Type.Kind() == reflect.Struct && innerField.Type != configType {
+			found, structPath, err := findStructNode(currentPath, &innerField, config, opts)
+			if err != nil {
+				return err
+			}
+			absent, err := isAbsentOptional(&innerField, found)
+			if err != nil {
+				return err
+			}
+			if absent {
+				opts.recordPreset(fieldName(field.name, &innerField), structPath)
+				continue
+			}
+			defaulted, err := loadDefaultStruct(structPath, field.name, &innerField, innerValue, found, opts)
+			if err != nil {
+				return err
+			}
+			if defaulted {
+				continue
+			}
+			wrapper := &fieldWrapper{inner: &innerField, name: fieldName(field.name, &innerField), path: structPath}
+			if err := loadStruct(currentPath, wrapper, innerValue.Addr(), config, opts); err != nil {
 				return err
 			}
 		} else {
-			if err := loadValue(currentPath, &innerField, fieldValue.Elem().FieldByName(innerField.Name).Addr(), config); err != nil {
+			if err := loadValue(currentPath, field.name, &innerField, innerValue.Addr(), config, opts); err != nil {
 				return err
 			}
 		}
@@ -130,31 +223,276 @@ func loadStruct(parentPath string, field *fieldWrapper, fieldValue reflect.Value
 	return nil
 }
 
+// findAlias looks for the value by alias paths of the field if there is no value by its path, the first found
+// alias wins. It returns the value and the path it is found by. Usage of the field tagged as deprecated is
+// reported to the logger: the usage of any alias or the usage of the path itself if the field has no aliases.
+func findAlias(field *reflect.StructField, hoconValue value, currentPath string, tagMap map[string]string,
+	config *objectValue, opts *options) (value, string, error) {
+	deprecated := false
+	if rawDeprecated, exists := tagMap[deprecatedKey]; exists {
+		var err error
+		if deprecated, err = parseBool(rawDeprecated); err != nil {
+			return nil, "", fmt.Errorf("wrong %s tag key for %s [%s]: %w", deprecatedKey, field.Name, field.Tag, err)
+		}
+	}
+	aliases := tagAliases(field.Tag)
+	aliasValues := make([]value, len(aliases))
+	for i, alias := range aliases {
+		path, err := parsePath(alias)
+		if err != nil {
+			return nil, "", fmt.Errorf("wrong alias for %s [%s]: %w", field.Name, field.Tag, err)
+		}
+		// all the aliases are known keys even if they are shadowed
+		aliasValues[i] = opts.find(config, path)
+		opts.markUsed(aliasValues[i])
+	}
+
+	if hoconValue != nil {
+		if deprecated && len(aliases) == 0 {
+			opts.log(LevelWarn, fmt.Sprintf("deprecated key %s is used", currentPath), map[string]interface{}{
+				"field": field.Name, "path": currentPath, "origin": hoconValue.pos()})
+		}
+		return hoconValue, currentPath, nil
+	}
+
+	for i, found := range aliasValues {
+		if _, isNull := found.(*nullValue); found == nil || isNull {
+			continue
+		}
+		if deprecated {
+			opts.log(LevelWarn, fmt.Sprintf("deprecated key %s is used, use %s instead", aliases[i], currentPath),
+				map[string]interface{}{"field": field.Name, "path": aliases[i], "replacement": currentPath,
+					"origin": found.pos()})
+		}
+		return found, aliases[i], nil
+	}
+	return nil, currentPath, nil
+}
+
+// isIgnored tells if the field is tagged with `hocon:"-"` to be skipped by the loader.
+func isIgnored(field *reflect.StructField) bool {
+	return field.Tag.Get("hocon") == ignoredTag
+}
+
+// isOptional tells if the field is tagged with optional key.
+func isOptional(field *reflect.StructField, tagMap map[string]string) (bool, error) {
+	rawOptional, exists := tagMap[optionalKey]
+	if !exists {
+		return false, nil
+	}
+	optional, err := parseBool(rawOptional)
+	if err != nil {
+		return false, fmt.Errorf("wrong %s tag key for %s [%s]: %w", optionalKey, field.Name, field.Tag, err)
+	}
+	return optional, nil
+}
+
+// findStructNode returns the value of the struct field and the path it is found by, which is an alias of the
+// field if there is no value by its own path. The value is nil if there is no value by any of the paths.
+func findStructNode(parentPath string, field *reflect.StructField, config *objectValue, opts *options) (value,
+	string, error) {
+	tagMap, err := mapTag(field.Tag)
+	if err != nil {
+		return nil, "", err
+	}
+	currentPath, err := getPath(parentPath, field, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	path, err := parsePath(currentPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("wrong path for %s [%s]: %w", field.Name, field.Tag, err)
+	}
+	found := opts.find(config, path)
+	if _, isNull := found.(*nullValue); isNull {
+		found = nil
+	}
+	return findAlias(field, found, currentPath, tagMap, config, opts)
+}
+
+// isAbsentOptional tells if the struct field is optional and there is no value found for it, such structs are
+// left untouched.
+func isAbsentOptional(field *reflect.StructField, found value) (bool, error) {
+	tagMap, err := mapTag(field.Tag)
+	if err != nil {
+		return false, err
+	}
+	optional, err := isOptional(field, tagMap)
+	if err != nil || !optional {
+		return false, err
+	}
+	return found == nil, nil
+}
+
+// loadDefaultStruct loads the struct field from its default value written as HOCON object, like `default={a: 1}`,
+// if there is no value found for it. It tells if the default value is used.
+func loadDefaultStruct(currentPath string, parentName string, field *reflect.StructField, fieldValue reflect.Value,
+	found value, opts *options) (bool, error) {
+	tagMap, err := mapTag(field.Tag)
+	if err != nil {
+		return false, err
+	}
+	rawDefault, hasDefault := tagMap[defaultKey]
+	if !hasDefault || found != nil {
+		return false, nil
+	}
+	if opts.merge {
+		// the field keeps its current value in merge mode
+		opts.recordPreset(fieldName(parentName, field), currentPath)
+		return true, nil
+	}
+
+	defaultValue, err := parseDefault(rawDefault)
+	if err != nil {
+		return false, fmt.Errorf("wrong default value for %s [%s]: %w", field.Name, field.Tag, err)
+	}
+	obj, ok := defaultValue.(*objectValue)
+	if !ok {
+		return false, fmt.Errorf("wrong default value for %s [%s]: cannot use %s as struct", field.Name, field.Tag,
+			typeName(defaultValue))
+	}
+	// fields of the default value are not reported, their paths are relative to the default object
+	nested := *opts
+	nested.report = nil
+	if err := loadConfig(obj, fieldValue.Addr().Interface(), &nested); err != nil {
+		return false, fmt.Errorf("wrong default value for %s [%s]: %w", field.Name, field.Tag, err)
+	}
+	opts.record(fieldName(parentName, field), currentPath, nil)
+	opts.logDefault(parentName, field, currentPath, rawDefault)
+	return true, nil
+}
+
+// embeddedStruct returns the type of embedded struct or embedded pointer to struct.
+func embeddedStruct(field *reflect.StructField) (reflect.Type, bool) {
+	if !field.Anonymous {
+		return nil, false
+	}
+	typ := field.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || typ == configType {
+		return nil, false
+	}
+	return typ, true
+}
+
+// isFlattened tells if the fields of embedded struct belong to the parent path. Embedded structs with path or
+// node tag keys get their own node like ordinary struct fields.
+func isFlattened(field *reflect.StructField) bool {
+	tagMap, err := mapTag(field.Tag)
+	if err != nil {
+		return false
+	}
+	_, hasPath := tagMap[pathKey]
+	_, hasNode := tagMap[nodeKey]
+	return !hasPath && !hasNode
+}
+
+// fieldName returns Go path of the field like `Inner.Field1` to be used in reports.
+func fieldName(parentName string, field *reflect.StructField) string {
+	if parentName == "" {
+		return field.Name
+	}
+	return parentName + "." + field.Name
+}
+
 // loadValue loads value from config to fieldValue. It's a terminal method for recursive cycle of loadStruct.
-func loadValue(parentPath string, field *reflect.StructField, fieldValue reflect.Value, config *configuration.Config) error {
+func loadValue(parentPath string, parentName string, field *reflect.StructField, fieldValue reflect.Value,
+	config *objectValue, opts *options) error {
 	tagMap, err := mapTag(field.Tag)
 	if err != nil {
 		return err
 	}
+	optional, err := isOptional(field, tagMap)
+	if err != nil {
+		return err
+	}
 
 	// it's impossible to get error here while the only way to get it is give an element with incorrect tag and
 	// map tag is doing before this statement.
-	currentPath, _ := getPath(parentPath, field)
+	currentPath, _ := getPath(parentPath, field, opts)
+	path, err := parsePath(currentPath)
+	if err != nil {
+		return fmt.Errorf("wrong path for %s [%s]: %w", field.Name, field.Tag, err)
+	}
+	hoconValue := opts.find(config, path)
+	opts.markUsed(hoconValue)
+	if _, isNull := hoconValue.(*nullValue); isNull {
+		hoconValue = nil
+	}
+	if hoconValue, currentPath, err = findAlias(field, hoconValue, currentPath, tagMap, config, opts); err != nil {
+		return err
+	}
 
+	if rawFromFile, exists := tagMap[fromFileKey]; exists && hoconValue != nil {
+		fromFile, err := parseBool(rawFromFile)
+		if err != nil {
+			return fmt.Errorf("wrong %s tag key for %s [%s]: %w", fromFileKey, field.Name, field.Tag, err)
+		}
+		if fromFile {
+			if hoconValue, err = loadFile(hoconValue); err != nil {
+				return valueError(field, hoconValue, err)
+			}
+		}
+	}
 	typ := fieldValue.Elem().Type()
+	_, isString := hoconValue.(*stringValue)
+	// strings given to lists are decrypted element by element after they are split
+	splitLater := isString && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && !isBytesType(typ)
+	if hoconValue != nil && !splitLater {
+		decrypted, err := opts.decryptValue(hoconValue)
+		if err != nil {
+			return valueError(field, hoconValue, err)
+		}
+		hoconValue = decrypted
+	}
+
+	if hoconValue == nil && opts.merge {
+		// the field keeps its current value in merge mode
+		opts.recordPreset(fieldName(parentName, field), currentPath)
+		return nil
+	}
 
 	hasDefault := false
 	rawDefault := ""
 	if rawDefault, hasDefault = tagMap[defaultKey]; hasDefault {
-		if typ.Kind() == reflect.Slice {
-			return fmt.Errorf("slices do not support default value: %s [%s]", field.Name, field.Tag)
+		if typ == configType || typ == reflect.PtrTo(configType) {
+			return fmt.Errorf("configs do not support default value: %s [%s]", field.Name, field.Tag)
 		}
-	} else {
-		if !config.HasPath(currentPath) {
-			return fmt.Errorf("no value either default value provided for %s [%s]", field.Name, field.Tag)
+	} else if hoconValue == nil {
+		if optional {
+			// the field keeps its preset value
+			opts.recordPreset(fieldName(parentName, field), currentPath)
+			return nil
+		}
+		return fmt.Errorf("no value either default value provided for %s [%s]", field.Name, field.Tag)
+	}
+
+	var defaultValue reflect.Value
+	if hasDefault {
+		// we must check the correctness of default value even if value is provided
+		if defaultValue, err = parseTypedDefault(typ, rawDefault, tagMap, opts); err != nil {
+			return fmt.Errorf("wrong default value for %s [%s]: %w", field.Name, field.Tag, err)
 		}
 	}
 
+	if isBytesType(typ) {
+		encoding := bytesEncoding(tagMap)
+		opts.record(fieldName(parentName, field), currentPath, hoconValue)
+		if hoconValue == nil {
+			opts.logDefault(parentName, field, currentPath, rawDefault)
+			fieldValue.Elem().Set(defaultValue)
+			return nil
+		}
+		typedValue, err1 := parseBytes(typ, hoconValue, encoding)
+		if err1 != nil {
+			return valueError(field, hoconValue, err1)
+		}
+		fieldValue.Elem().Set(typedValue)
+		return nil
+	}
+
 	switch typ.Kind() {
 	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
 		return fmt.Errorf("cannot use uint. Use int8/16/32/64 instead for %s [%s]", field.Name, field.Tag)
@@ -165,56 +503,182 @@ func loadValue(parentPath string, field *reflect.StructField, fieldValue reflect
 	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Float32, reflect.Float64, reflect.Bool:
 
-		var defaultValue *reflect.Value
-		if hasDefault {
-			// we must check the correctness of default value even if value is provided
-			var err1 error
-			defaultValue, err1 = parseType(typ, rawDefault)
-			if err1 != nil {
-				return fmt.Errorf("wrong default value for %s [%s]: %w", field.Name, field.Tag, err1)
-			}
-		}
-
-		hoconValue := config.GetValue(currentPath)
-
 		value, err := parseHoconValue(typ, hoconValue)
 		if err != nil {
-			return fmt.Errorf("wrong value for %s [%s]: %w", field.Name, field.Tag, err)
+			return valueError(field, hoconValue, err)
 		}
 
+		opts.record(fieldName(parentName, field), currentPath, hoconValue)
 		if value != nil {
+			if typ.Kind() == reflect.Float32 {
+				opts.checkPrecision(parentName, field, currentPath, hoconValue)
+			}
 			fieldValue.Elem().Set(*value)
 			return nil
 		}
-		fieldValue.Elem().Set(*defaultValue)
+		opts.logDefault(parentName, field, currentPath, rawDefault)
+		fieldValue.Elem().Set(defaultValue)
 		return nil
 
 	case reflect.String:
-		typedValue := config.GetString(currentPath, rawDefault)
+		opts.record(fieldName(parentName, field), currentPath, hoconValue)
+		if hoconValue == nil {
+			opts.logDefault(parentName, field, currentPath, rawDefault)
+			fieldValue.Elem().Set(defaultValue)
+			return nil
+		}
+		typedValue, ok := text(hoconValue)
+		if !ok {
+			return valueError(field, hoconValue, fmt.Errorf("cannot use %s as string", typeName(hoconValue)))
+		}
 		fieldValue.Elem().SetString(typedValue)
 
-	case reflect.Slice:
-		list := config.GetStringList(currentPath)
-		value := reflect.ValueOf(list)
-		typedValue, err1 := parseList(typ, value)
+	case reflect.Slice, reflect.Array:
+		sep, hasSep := tagMap[sepKey]
+		if !hasSep {
+			sep = defaultSep
+		} else if sep == "" {
+			return fmt.Errorf("empty %s for %s [%s]", sepKey, field.Name, field.Tag)
+		}
+		opts.record(fieldName(parentName, field), currentPath, hoconValue)
+		if hoconValue == nil {
+			opts.logDefault(parentName, field, currentPath, rawDefault)
+			fieldValue.Elem().Set(defaultValue)
+			return nil
+		}
+		list, ok := hoconValue.(*arrayValue)
+		if obj, isObject := hoconValue.(*objectValue); isObject {
+			var err1 error
+			if list, ok, err1 = indexedList(obj); err1 != nil {
+				return valueError(field, hoconValue, err1)
+			}
+		} else if !ok {
+			list, ok = splitList(hoconValue, sep)
+		}
+		if !ok {
+			return valueError(field, hoconValue, fmt.Errorf("cannot use %s as list", typeName(hoconValue)))
+		}
+		if splitLater {
+			decrypted, err1 := opts.decryptValue(list)
+			if err1 != nil {
+				return valueError(field, hoconValue, err1)
+			}
+			list = decrypted.(*arrayValue)
+		}
+		typedValue, err1 := parseList(field, typ, list, opts)
 		if err1 != nil {
 			return err1
 		}
 		fieldValue.Elem().Set(typedValue)
 
+	case reflect.Struct, reflect.Ptr:
+		if typ != configType && typ != reflect.PtrTo(configType) {
+			return fmt.Errorf("unimplemented data type %s", typ.String())
+		}
+		obj, ok := hoconValue.(*objectValue)
+		if !ok {
+			return valueError(field, hoconValue, fmt.Errorf("cannot use %s as config", typeName(hoconValue)))
+		}
+		opts.record(fieldName(parentName, field), currentPath, hoconValue)
+		if typ.Kind() == reflect.Ptr {
+			fieldValue.Elem().Set(reflect.ValueOf(&Config{root: obj}))
+		} else {
+			fieldValue.Elem().Set(reflect.ValueOf(Config{root: obj}))
+		}
+
+	case reflect.Interface, reflect.Map:
+		if opts.isPolymorphicType(typ) {
+			if hoconValue == nil {
+				return fmt.Errorf("interfaces do not support default value: %s [%s]", field.Name, field.Tag)
+			}
+			typedValue, err1 := opts.parsePolymorphic(typ, hoconValue)
+			if err1 != nil {
+				return valueError(field, hoconValue, err1)
+			}
+			opts.record(fieldName(parentName, field), currentPath, hoconValue)
+			fieldValue.Elem().Set(typedValue)
+			return nil
+		}
+		if !isGenericType(typ) {
+			return fmt.Errorf("unimplemented data type %s", typ.String())
+		}
+		opts.record(fieldName(parentName, field), currentPath, hoconValue)
+		if hoconValue == nil {
+			opts.logDefault(parentName, field, currentPath, rawDefault)
+			fieldValue.Elem().Set(defaultValue)
+			return nil
+		}
+		typedValue, err1 := parseGeneric(typ, hoconValue)
+		if err1 != nil {
+			return valueError(field, hoconValue, err1)
+		}
+		if opts.merge && typ == genericMapType && !fieldValue.Elem().IsNil() {
+			current := fieldValue.Elem().Interface().(map[string]interface{})
+			typedValue = reflect.ValueOf(mergeGenericMaps(current, typedValue.Interface().(map[string]interface{})))
+		}
+		fieldValue.Elem().Set(typedValue)
+
 	default:
 		return fmt.Errorf("unimplemented data type %s", typ.Kind().String())
 	}
 	return nil
 }
 
-func parseType(typ reflect.Type, stringValue string) (*reflect.Value, error) {
-	conf := configuration.ParseString(fmt.Sprintf("{k:%s}", stringValue))
-	return parseHoconValue(typ, conf.GetValue("k"))
+// valueError returns an error about wrong config value of the field pointing to the origin of the value.
+func valueError(field *reflect.StructField, hoconValue value, err error) error {
+	return fmt.Errorf("wrong value for %s [%s] at %s: %w", field.Name, field.Tag, hoconValue.pos(), err)
+}
+
+// parseDefault parses the default value of the tag as a HOCON literal, like `[1, 2, 3]` or `{a: 1}`. Default
+// values are literals, substitutions are neither looked for in the environment nor given to resolvers.
+func parseDefault(rawDefault string) (value, error) {
+	root, err := parse("", defaultKey+" = "+rawDefault, newIncluder(newOptions(nil)), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if hasSubstitutions(root) {
+		return nil, fmt.Errorf("substitutions are not supported in default values")
+	}
+	// only concatenations like `[1] [2]` are left to resolve
+	root, err = resolve(root, nil)
+	if err != nil {
+		return nil, err
+	}
+	return root.fields[defaultKey], nil
+}
+
+// hasSubstitutions tells if there is a substitution in the unresolved value.
+func hasSubstitutions(v value) bool {
+	var parts []value
+	switch typed := v.(type) {
+	case *substValue:
+		return true
+	case *objectValue:
+		for _, key := range typed.keys {
+			parts = append(parts, typed.fields[key])
+		}
+	case *arrayValue:
+		parts = typed.elements
+	case *concatValue:
+		parts = typed.parts
+	case *mergeValue:
+		parts = typed.parts
+	}
+	for _, part := range parts {
+		if hasSubstitutions(part) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseType parses given string according to given reflect.Type and returns reflect.Value of this type.
+func parseType(typ reflect.Type, rawValue string) (*reflect.Value, error) {
+	return parseHoconValue(typ, &stringValue{text: rawValue})
 }
 
 // parseHoconValue parses given hoconValue according to given reflect.Type and returns reflect.Value of this type.
-func parseHoconValue(typ reflect.Type, hoconValue *hocon.HoconValue) (*reflect.Value, error) {
+func parseHoconValue(typ reflect.Type, hoconValue value) (*reflect.Value, error) {
 	if hoconValue == nil {
 		return nil, nil
 	}
@@ -255,65 +719,260 @@ func getTargetTypeValue(typ reflect.Type, value *reflect.Value) (*reflect.Value,
 
 // getExpandedValueSafely returns 64 bit value of ints and floats
 // and regular value of others
-func getExpandedValueSafely(typ reflect.Type, hoconValue *hocon.HoconValue) (*reflect.Value, error) {
+func getExpandedValueSafely(typ reflect.Type, hoconValue value) (*reflect.Value, error) {
+	rawValue, ok := text(hoconValue)
+	if !ok {
+		return nil, fmt.Errorf("cannot use %s as %s", typeName(hoconValue), typ.Kind())
+	}
+
 	var value interface{}
 	var err error
 
 	switch typ.Kind() {
 	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		value, err = hoconValue.GetInt64Safely()
+		value, err = strconv.ParseInt(rawValue, 10, 64)
 		if err != nil {
 			return nil, err
 		}
 	case reflect.Float32, reflect.Float64:
-		value, err = hoconValue.GetFloat64Safely()
+		value, err = strconv.ParseFloat(rawValue, 64)
 		if err != nil {
 			return nil, err
 		}
 	case reflect.Bool:
-		value, err = hoconValue.GetBooleanSafely()
+		value, err = parseBool(rawValue)
 		if err != nil {
 			return nil, err
 		}
 	case reflect.String:
-		value = hoconValue.GetString()
+		value = rawValue
 	}
 	reflectValue := reflect.ValueOf(value)
 	return &reflectValue, nil
 }
 
+// parseBool parses HOCON boolean which may be written as true/false, yes/no or on/off in any case.
+func parseBool(rawValue string) (bool, error) {
+	switch strings.ToLower(rawValue) {
+	case "on", "true", "yes":
+		return true, nil
+	case "off", "false", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown boolean format: %s", rawValue)
+}
+
 // parseList parses given slice's values according to given reflect.Type and
-// returns reflect.Value of slice of this type.
-func parseList(typ reflect.Type, listValue reflect.Value) (reflect.Value, error) {
-	sliceValue := reflect.MakeSlice(typ, listValue.Len(), listValue.Cap())
-	for i := 0; i < listValue.Len(); i++ {
-		res, err := parseType(typ.Elem(), listValue.Index(i).Interface().(string))
+// returns reflect.Value of slice or array of this type. Arrays must have the same length as the list. Field is nil
+// for default values.
+func parseList(field *reflect.StructField, typ reflect.Type, listValue *arrayValue, opts *options) (reflect.Value,
+	error) {
+	var sliceValue reflect.Value
+	if typ.Kind() == reflect.Array {
+		if len(listValue.elements) != typ.Len() {
+			return reflect.Value{}, listError(field, listValue,
+				fmt.Errorf("cannot use %d elements as %s", len(listValue.elements), typ))
+		}
+		sliceValue = reflect.New(typ).Elem()
+	} else {
+		sliceValue = reflect.MakeSlice(typ, len(listValue.elements), len(listValue.elements))
+	}
+	for i, element := range listValue.elements {
+		if opts.isPolymorphicType(typ.Elem()) {
+			res, err := opts.parsePolymorphic(typ.Elem(), element)
+			if err != nil {
+				return reflect.Value{}, listError(field, element, err)
+			}
+			sliceValue.Index(i).Set(res)
+			continue
+		}
+		if isGenericType(typ.Elem()) {
+			res, err := parseGeneric(typ.Elem(), element)
+			if err != nil {
+				return reflect.Value{}, listError(field, element, err)
+			}
+			sliceValue.Index(i).Set(res)
+			continue
+		}
+		res, err := parseHoconValue(typ.Elem(), element)
 		if err != nil {
-			return reflect.Value{}, err
+			return reflect.Value{}, listError(field, element, err)
 		}
 		sliceValue.Index(i).Set(*res)
 	}
 	return sliceValue, nil
 }
 
-// mapTag parses StructTag to aux Tag struct.
-func mapTag(structTag reflect.StructTag) (map[string]string, error) {
-	stringTag := structTag.Get("hocon")
-	tagMap := make(map[string]string)
-	if stringTag != "" {
-		for _, item := range strings.Split(stringTag, ",") {
-			pair := strings.Split(item, "=")
-			if len(pair) != 2 {
-				return nil, fmt.Errorf("tag format error: %s", stringTag)
-			}
-			key, value := pair[0], pair[1]
+// listError wraps the error of the list element with the field, errors of default values are not wrapped as
+// the field is nil for them.
+func listError(field *reflect.StructField, element value, err error) error {
+	if field == nil {
+		return err
+	}
+	return valueError(field, element, err)
+}
 
-			if _, exists := tagKeys[key]; exists {
-				tagMap[key] = value
-			}
+// splitList makes a list of a string value split by the separator, like `a, b, c` given by an environment
+// variable. Elements are trimmed and keep the origin of the string, an empty string is an empty list. It returns
+// false for objects and arrays.
+func splitList(hoconValue value, sep string) (*arrayValue, bool) {
+	rawValue, ok := text(hoconValue)
+	if !ok {
+		return nil, false
+	}
+	list := &arrayValue{Origin: hoconValue.pos()}
+	if strings.TrimSpace(rawValue) == "" {
+		return list, true
+	}
+	for _, element := range strings.Split(rawValue, sep) {
+		list.elements = append(list.elements, &stringValue{Origin: hoconValue.pos(), text: strings.TrimSpace(element)})
+	}
+	return list, true
+}
+
+// indexedList converts the object with numeric keys, like `a.0 = x, a.1 = y` given by properties, to the list
+// ordered by the keys as HOCON spec suggests, gaps between the keys are skipped. Keys which are not indexes
+// according to listIndex are ignored. It returns false for objects without numeric keys.
+func indexedList(obj *objectValue) (*arrayValue, bool, error) {
+	type indexed struct {
+		index   int
+		element value
+	}
+	var elements []indexed
+	for _, key := range obj.keys {
+		index, ok, err := listIndex(key)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			continue
+		}
+		elements = append(elements, indexed{index, obj.fields[key]})
+	}
+	if len(elements) == 0 {
+		return nil, false, nil
+	}
+	sort.Slice(elements, func(i, j int) bool {
+		return elements[i].index < elements[j].index
+	})
+	list := &arrayValue{Origin: obj.Origin}
+	for _, element := range elements {
+		list.elements = append(list.elements, element.element)
+	}
+	return list, true, nil
+}
+
+// parseDefaultList parses the default value of the slice or array field written as HOCON array.
+func parseDefaultList(typ reflect.Type, rawDefault string, opts *options) (reflect.Value, error) {
+	defaultValue, err := parseDefault(rawDefault)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	list, ok := defaultValue.(*arrayValue)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("cannot use %s as list", typeName(defaultValue))
+	}
+	return parseList(nil, typ, list, opts)
+}
+
+// parseDefaultGeneric parses the default value of interface{} or map field written as HOCON literal.
+func parseDefaultGeneric(typ reflect.Type, rawDefault string) (reflect.Value, error) {
+	defaultValue, err := parseDefault(rawDefault)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return parseGeneric(typ, defaultValue)
+}
+
+// parseTypedDefault parses the default value of the tag according to the type of the field. It returns invalid
+// value for types which do not support default values, loadValue reports them.
+func parseTypedDefault(typ reflect.Type, rawDefault string, tagMap map[string]string, opts *options) (reflect.Value,
+	error) {
+	if isBytesType(typ) {
+		return parseBytes(typ, &stringValue{text: rawDefault}, bytesEncoding(tagMap))
+	}
+	switch typ.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		value, err := parseType(typ, rawDefault)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return *value, nil
+	case reflect.String:
+		return reflect.ValueOf(rawDefault).Convert(typ), nil
+	case reflect.Slice, reflect.Array:
+		return parseDefaultList(typ, rawDefault, opts)
+	case reflect.Interface, reflect.Map:
+		if !opts.isPolymorphicType(typ) && isGenericType(typ) {
+			return parseDefaultGeneric(typ, rawDefault)
+		}
+	}
+	return reflect.Value{}, nil
+}
+
+// isGenericType tells if the type is interface{} or map[string]interface{} which take free-form values.
+func isGenericType(typ reflect.Type) bool {
+	return typ == interfaceType || typ == genericMapType
+}
+
+// parseGeneric converts the value to JSON-like representation for interface{} and map[string]interface{} types.
+func parseGeneric(typ reflect.Type, hoconValue value) (reflect.Value, error) {
+	if typ == genericMapType {
+		if _, ok := hoconValue.(*objectValue); !ok {
+			return reflect.Value{}, fmt.Errorf("cannot use %s as map", typeName(hoconValue))
+		}
+	}
+	generic := toInterface(hoconValue)
+	if generic == nil {
+		return reflect.Zero(typ), nil
+	}
+	return reflect.ValueOf(generic), nil
+}
+
+// mergeGenericMaps returns a copy of the current map with the loaded one merged over it. Nested maps are merged
+// the same way, other values are replaced.
+func mergeGenericMaps(current map[string]interface{}, loaded map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(current)+len(loaded))
+	for key, v := range current {
+		result[key] = v
+	}
+	for key, v := range loaded {
+		currentMap, currentIsMap := result[key].(map[string]interface{})
+		loadedMap, loadedIsMap := v.(map[string]interface{})
+		if currentIsMap && loadedIsMap {
+			result[key] = mergeGenericMaps(currentMap, loadedMap)
+		} else {
+			result[key] = v
 		}
 	}
-	return tagMap, nil
+	return result
+}
+
+// loadFile reads the file named by given value and returns its trimmed content as a string value.
+func loadFile(filenameValue value) (value, error) {
+	filename, ok := text(filenameValue)
+	if !ok {
+		return filenameValue, fmt.Errorf("cannot use %s as file name", typeName(filenameValue))
+	}
+	content, err := readValueFile(filename)
+	if err != nil {
+		return filenameValue, err
+	}
+	return &stringValue{Origin: filenameValue.pos(), text: content}, nil
+}
+
+// readValueFile reads a file which contains a single value, like a mounted secret. Leading and trailing
+// whitespaces are trimmed.
+func readValueFile(filename string) (string, error) {
+	if err := checkFileAccessibility(filename); err != nil {
+		return "", fmt.Errorf("cannot read value file: %w", err)
+	}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("cannot read value file: %w", err)
+	}
+	return strings.TrimSpace(string(content)), nil
 }
 
 // checkFileAccessibility checks if a file accessible and is not a directory before we
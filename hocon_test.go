@@ -1,6 +1,8 @@
 package hocon
 
 import (
+	"encoding/base64"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"log"
@@ -46,6 +48,29 @@ func assertErrRegex(t *testing.T, err error, regex string) {
 	}
 }
 
+type logRecord struct {
+	level  Level
+	msg    string
+	fields map[string]interface{}
+}
+
+// recordingLogger keeps the messages of given level and above.
+func recordingLogger(minLevel Level, records *[]logRecord) Option {
+	return WithLogger(func(level Level, msg string, fields map[string]interface{}) {
+		if level >= minLevel {
+			*records = append(*records, logRecord{level, msg, fields})
+		}
+	})
+}
+
+func messages(records []logRecord) []string {
+	var result []string
+	for _, record := range records {
+		result = append(result, record.level.String()+": "+record.msg)
+	}
+	return result
+}
+
 func TestCorrectDefaultRanges(t *testing.T) {
 	props1 := struct {
 		FMin1 int8  `hocon:"default=-128"`
@@ -208,7 +233,7 @@ func TestCorrectStringDefault(t *testing.T) {
 		Field2 string `hocon:"default=abc"`
 		Field3 string `hocon:"default="`
 	}{}
-	err := LoadConfigText("{Field2:}", &props1)
+	err := LoadConfigText(`{Field2:""}`, &props1)
 	if assert.Nil(t, err) {
 		assert.Equal(t, "1e3", props1.Field1)
 		assert.Equal(t, "", props1.Field2)
@@ -216,6 +241,16 @@ func TestCorrectStringDefault(t *testing.T) {
 	}
 }
 
+func TestEmptyValue(t *testing.T) {
+	props1 := struct {
+		Field2 string `hocon:"default=abc"`
+	}{}
+	err := LoadConfigText("{Field2:}", &props1)
+	if assert.Error(t, err) {
+		assert.Equal(t, "1:9: expected value for key Field2, got '}'", err.Error())
+	}
+}
+
 func TestIncorrectFloat32(t *testing.T) {
 	props1 := struct {
 		Field1 float32
@@ -517,6 +552,113 @@ func TestPathByPath(t *testing.T) {
 	}
 }
 
+type EmbeddedBase struct {
+	Field1 string
+	Field2 string `hocon:"default=d2"`
+}
+
+type embeddedHidden struct {
+	Field3 string
+}
+
+type EmbeddedPointer struct {
+	Field4 string
+}
+
+func TestEmbeddedStructs(t *testing.T) {
+	props1 := struct {
+		EmbeddedBase
+		embeddedHidden
+		*EmbeddedPointer
+		Field5 string
+		field6 string
+	}{field6: "preset"}
+	err := LoadConfigText("{Field1:c1,Field3:c3,Field4:c4,Field5:c5,field6:c6}", &props1)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "c1", props1.Field1)
+		assert.Equal(t, "d2", props1.Field2)
+		assert.Equal(t, "c3", props1.Field3)
+		if assert.NotNil(t, props1.EmbeddedPointer) {
+			assert.Equal(t, "c4", props1.Field4)
+		}
+		assert.Equal(t, "c5", props1.Field5)
+		assert.Equal(t, "preset", props1.field6)
+		assert.Equal(t, "Field1 = \"c1\"\nField2 = \"d2\"\nField3 = \"c3\"\nField4 = \"c4\"\nField5 = \"c5\"\n", Dump(props1))
+	}
+}
+
+func TestEmbeddedStructNode(t *testing.T) {
+	props1 := struct {
+		EmbeddedBase     `hocon:"node=base"`
+		*EmbeddedPointer `hocon:"path=x.y"`
+	}{EmbeddedPointer: &EmbeddedPointer{}}
+	err := LoadConfigText("{base:{Field1:c1,Field2:c2},x.y.Field4:c4}", &props1)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "c1", props1.Field1)
+		assert.Equal(t, "c2", props1.Field2)
+		assert.Equal(t, "c4", props1.Field4)
+		assert.Equal(t, "base {\n  Field1 = \"c1\"\n  Field2 = \"c2\"\n}\nx {\n  y {\n    Field4 = \"c4\"\n  }\n}\n",
+			Dump(props1))
+	}
+}
+
+func TestIgnoredField(t *testing.T) {
+	props1 := struct {
+		Field1 string
+		Field2 string `hocon:"-"`
+		Inner1 struct {
+			Field3 string
+		} `hocon:"-"`
+	}{Field2: "preset"}
+	err := LoadConfigText("{Field1:c1,Field2:c2}", &props1)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "c1", props1.Field1)
+		assert.Equal(t, "preset", props1.Field2)
+		assert.Equal(t, "Field1 = \"c1\"\n", Dump(props1))
+	}
+}
+
+func TestOptionalField(t *testing.T) {
+	props1 := struct {
+		Field1 string  `hocon:"optional=true"`
+		Field2 int32   `hocon:"optional=true"`
+		Field3 []int32 `hocon:"optional=true"`
+		Field4 string  `hocon:"optional=true,default=d4"`
+		Field5 string  `hocon:"optional=true"`
+		Inner1 struct {
+			Field6 string
+		} `hocon:"optional=true"`
+		Inner2 struct {
+			Field7 string `hocon:"optional=true"`
+		} `hocon:"optional=true"`
+	}{Field1: "preset", Field2: 2, Field3: []int32{3}}
+	props1.Inner1.Field6 = "preset"
+	err := LoadConfigText("{Field5:c5,Field2:null,Inner2{Field7:c7}}", &props1)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "preset", props1.Field1)
+		assert.Equal(t, int32(2), props1.Field2)
+		assert.Equal(t, []int32{3}, props1.Field3)
+		assert.Equal(t, "d4", props1.Field4)
+		assert.Equal(t, "c5", props1.Field5)
+		assert.Equal(t, "preset", props1.Inner1.Field6)
+		assert.Equal(t, "c7", props1.Inner2.Field7)
+	}
+
+	props2 := struct {
+		Field1 string `hocon:"optional=maybe"`
+	}{}
+	err = LoadConfigText("{Field1:c1}", &props2)
+	assertErrRegex(t, err, `^wrong optional tag key for Field1 \[hocon:"optional=maybe"\]`)
+
+	props3 := struct {
+		Inner1 struct {
+			Field1 string
+		} `hocon:"optional=false"`
+	}{}
+	err = LoadConfigText("{}", &props3)
+	assertErrRegex(t, err, "^no value either default value provided for Field1")
+}
+
 func TestNodeAndPath(t *testing.T) {
 	props1 := struct {
 		Inner1 struct {
@@ -656,27 +798,858 @@ func TestUnimplemented(t *testing.T) {
 	assert.Error(t, err1)
 }
 
-func TestPanicTextConfig(t *testing.T) {
-	assert.Panics(t, func() {
-		props1 := struct{}{}
-		err1 := LoadConfigText("{F:1+1}", &props1)
-		assert.Error(t, err1)
-	})
+func TestSyntaxErrorTextConfig(t *testing.T) {
+	props1 := struct{}{}
+	err1 := LoadConfigText("{F:1+1}", &props1)
+	if assert.Error(t, err1) {
+		assert.Regexp(t, "^1:5: '\\+' is not allowed", err1)
+	}
 }
 
-func TestPanicFileConfig(t *testing.T) {
-	file, err1 := makeTestFile("panic.conf")
+func TestSyntaxErrorFileConfig(t *testing.T) {
+	file, err1 := makeTestFile("syntax.conf")
 	if assert.Nil(t, err1, "cannot create temp file") {
 		defer func() {
 			_ = deleteTestFile(file)
 		}()
-		_, err2 := file.WriteString("{F:1+1}")
+		_, err2 := file.WriteString("{\n  F:1+1\n}")
 		if assert.Nil(t, err2) {
-			assert.Panics(t, func() {
-				props1 := struct{}{}
-				err1 := LoadConfigFile(file.Name(), &props1)
-				assert.Error(t, err1)
-			})
+			props1 := struct{}{}
+			err3 := LoadConfigFile(file.Name(), &props1)
+			if assert.Error(t, err3) {
+				assert.Regexp(t, "syntax.conf.*:2:6: '\\+' is not allowed", err3)
+				var parseErr *ParseError
+				if assert.True(t, errors.As(err3, &parseErr)) {
+					assert.Equal(t, file.Name(), parseErr.Filename)
+					assert.Equal(t, 2, parseErr.Line)
+					assert.Equal(t, 6, parseErr.Column)
+				}
+			}
+		}
+	}
+}
+
+func TestFromFile(t *testing.T) {
+	passwordFile, err1 := makeTestFile("password.txt")
+	portFile, err2 := makeTestFile("port.txt")
+	if assert.Nil(t, err1, "cannot create temp file") && assert.Nil(t, err2, "cannot create temp file") {
+		defer func() {
+			_ = deleteTestFile(passwordFile)
+			_ = deleteTestFile(portFile)
+		}()
+		_, err3 := passwordFile.WriteString("  s3cr3t\n")
+		_, err4 := portFile.WriteString("5432\n")
+		if assert.Nil(t, err3) && assert.Nil(t, err4) {
+			assert.Nil(t, os.Setenv("HOCON_TEST_PASSWORD_FILE", passwordFile.Name()))
+			defer func() {
+				_ = os.Unsetenv("HOCON_TEST_PASSWORD_FILE")
+			}()
+
+			props1 := struct {
+				Password string `hocon:"path=db.password,fromfile=true"`
+				Port     int32  `hocon:"path=db.port,fromfile=true"`
+				Name     string `hocon:"path=db.name,fromfile=true,default=app"`
+			}{}
+			err5 := LoadConfigText("db.password: ${HOCON_TEST_PASSWORD_FILE}\ndb.port: "+portFile.Name(), &props1)
+			if assert.Nil(t, err5) {
+				assert.Equal(t, "s3cr3t", props1.Password)
+				assert.Equal(t, int32(5432), props1.Port)
+				assert.Equal(t, "app", props1.Name)
+			}
+
+			props2 := struct {
+				Port int8 `hocon:"fromfile=true"`
+			}{}
+			err6 := LoadConfigText("\nPort: "+portFile.Name(), &props2)
+			if assert.Error(t, err6) {
+				assert.Regexp(t, "^wrong value for Port .* at 2:7: hocon: value out of range$", err6)
+			}
+		}
+	}
+}
+
+func TestFromFileErrors(t *testing.T) {
+	props1 := struct {
+		Password string `hocon:"fromfile=true"`
+	}{}
+	err1 := LoadConfigText("Password: /nonexistent/secret", &props1)
+	if assert.Error(t, err1) {
+		assert.Regexp(t, "^wrong value for Password .* at 1:11: cannot read value file: .*no such file or directory$", err1)
+	}
+
+	err2 := LoadConfigText("Password: tests", &props1)
+	if assert.Error(t, err2) {
+		assert.Regexp(t, "is a directory$", err2)
+	}
+
+	props2 := struct {
+		Password string `hocon:"fromfile=maybe"`
+	}{}
+	err3 := LoadConfigText("Password: tests", &props2)
+	if assert.Error(t, err3) {
+		assert.Regexp(t, "^wrong fromfile tag key for Password", err3)
+	}
+}
+
+func TestSplitStringToList(t *testing.T) {
+	assert.Nil(t, os.Setenv("TEST_SPLIT_HOSTS", "a, b ,c"))
+	defer func() {
+		_ = os.Unsetenv("TEST_SPLIT_HOSTS")
+	}()
+
+	props1 := struct {
+		Hosts   []string  `hocon:"path=hosts"`
+		Ports   []int32   `hocon:"path=ports"`
+		Paths   []string  `hocon:"path=paths,sep=:"`
+		Weights []float64 `hocon:"path=weights,sep=' ; '"`
+		Tags    []string  `hocon:"path=tags,default=[a, b]"`
+		Empty   []int32   `hocon:"path=empty"`
+	}{}
+	err1 := LoadConfigText(`
+hosts: ${TEST_SPLIT_HOSTS}
+ports: "1,2"
+paths: "/bin:/usr/bin"
+weights: "0.5 ; 1.5"
+empty: ""
+`, &props1)
+	if assert.Nil(t, err1) {
+		assert.Equal(t, []string{"a", "b", "c"}, props1.Hosts)
+		assert.Equal(t, []int32{1, 2}, props1.Ports)
+		assert.Equal(t, []string{"/bin", "/usr/bin"}, props1.Paths)
+		assert.Equal(t, []float64{0.5, 1.5}, props1.Weights)
+		assert.Equal(t, []string{"a", "b"}, props1.Tags)
+		assert.Equal(t, []int32{}, props1.Empty)
+	}
+
+	err2 := LoadConfigText("hosts: [x], ports: 80, paths: [], weights: [], tags: t, empty: []", &props1)
+	if assert.Nil(t, err2) {
+		assert.Equal(t, []int32{80}, props1.Ports)
+		assert.Equal(t, []string{"t"}, props1.Tags)
+	}
+}
+
+func TestSplitStringToListErrors(t *testing.T) {
+	props1 := struct {
+		Hosts []string `hocon:"path=hosts"`
+		Ports []int32  `hocon:"path=ports"`
+	}{}
+	err1 := LoadConfigText(`hosts: [], ports: "1,x"`, &props1)
+	assert.EqualError(t, err1, "wrong value for Ports [hocon:\"path=ports\"] at 1:19: "+
+		"strconv.ParseInt: parsing \"x\": invalid syntax")
+
+	err2 := LoadConfigText(`hosts: {}, ports: []`, &props1)
+	assert.EqualError(t, err2, "wrong value for Hosts [hocon:\"path=hosts\"] at 1:8: cannot use object as list")
+
+	props2 := struct {
+		Field1 []string `hocon:"sep=''"`
+	}{}
+	err3 := LoadConfigText("Field1: a", &props2)
+	assert.EqualError(t, err3, "empty sep for Field1 [hocon:\"sep=''\"]")
+}
+
+func TestIndexedObjectAsList(t *testing.T) {
+	props1 := struct {
+		Hosts []string
+		Ports []int32
+	}{}
+	err1 := LoadConfigText(`
+Hosts { 10: c, 2: b, 0: a, x: ignored, -1: ignored, "+3": ignored }
+Ports.1 = 81
+Ports.0 = 80
+`, &props1)
+	if assert.Nil(t, err1) {
+		assert.Equal(t, []string{"a", "b", "c"}, props1.Hosts)
+		assert.Equal(t, []int32{80, 81}, props1.Ports)
+	}
+}
+
+func TestIndexedObjectOverList(t *testing.T) {
+	props1 := struct {
+		Hosts []string
+		Ports []int32
+	}{}
+	err1 := LoadConfigText(`
+Hosts = [x, y, z]
+Hosts.1 = q
+Hosts.3 = w
+Ports = [80]
+Ports { 0: 81 }
+`, &props1)
+	if assert.Nil(t, err1) {
+		assert.Equal(t, []string{"x", "q", "z", "w"}, props1.Hosts)
+		assert.Equal(t, []int32{81}, props1.Ports)
+	}
+
+	err2 := LoadConfigText("Hosts = [x, y], Hosts.3 = w, Ports = []", &props1)
+	assert.EqualError(t, err2, "1:27: index 3 is past the end of the list of 2 elements")
+
+	err3 := LoadConfigText("Hosts = [x, y], Hosts.01 = q, Ports = []", &props1)
+	assert.EqualError(t, err3, "1:28: wrong index 01, leading zeros are not allowed")
+
+	// objects with other keys replace the list as HOCON spec says
+	err4 := LoadConfigText("Hosts = [x, y], Hosts { a: b, 1: q }, Ports = []", &props1)
+	if assert.Nil(t, err4) {
+		assert.Equal(t, []string{"q"}, props1.Hosts)
+	}
+}
+
+func TestIndexedObjectAsListErrors(t *testing.T) {
+	props1 := struct {
+		Hosts []string
+		Ports []int32
+	}{}
+	err1 := LoadConfigText("Hosts { a: x }, Ports: []", &props1)
+	assert.EqualError(t, err1, "wrong value for Hosts [] at 1:7: cannot use object as list")
+
+	err2 := LoadConfigText("Hosts: [], Ports { 0: x }", &props1)
+	assert.EqualError(t, err2, "wrong value for Ports [] at 1:23: strconv.ParseInt: parsing \"x\": invalid syntax")
+
+	err3 := LoadConfigText("Hosts { 1: a, 01: b }, Ports: []", &props1)
+	assert.EqualError(t, err3, "wrong value for Hosts [] at 1:7: wrong index 01, leading zeros are not allowed")
+}
+
+func TestLoadBytesAndArrays(t *testing.T) {
+	props1 := struct {
+		Key     []byte   `hocon:"path=key"`
+		Salt    [4]byte  `hocon:"path=salt,encoding=hex"`
+		Token   []byte   `hocon:"path=token,encoding=plain,default=none"`
+		Address [4]int32 `hocon:"path=address"`
+		Ports   [2]int32 `hocon:"path=ports,default=[80, 443]"`
+	}{}
+	err1 := LoadConfigText(`
+key: "aGVsbG8="
+salt: 0a0b0c0d
+address: [127, 0, 0, 1]
+`, &props1)
+	if assert.Nil(t, err1) {
+		assert.Equal(t, []byte("hello"), props1.Key)
+		assert.Equal(t, [4]byte{10, 11, 12, 13}, props1.Salt)
+		assert.Equal(t, []byte("none"), props1.Token)
+		assert.Equal(t, [4]int32{127, 0, 0, 1}, props1.Address)
+		assert.Equal(t, [2]int32{80, 443}, props1.Ports)
+		assert.Equal(t, "key = \"aGVsbG8=\"\nsalt = \"0a0b0c0d\"\ntoken = \"none\"\naddress = [127, 0, 0, 1]\n"+
+			"ports = [80, 443]\n", Dump(props1))
+	}
+
+	err2 := LoadConfigText(`key: aGVsbG8, salt: "0A0B0C0D", token: t, address: "10, 0, 0, 1", ports { 1: 2, 0: 1 }`,
+		&props1)
+	if assert.Nil(t, err2) {
+		assert.Equal(t, []byte("hello"), props1.Key)
+		assert.Equal(t, []byte("t"), props1.Token)
+		assert.Equal(t, [4]int32{10, 0, 0, 1}, props1.Address)
+		assert.Equal(t, [2]int32{1, 2}, props1.Ports)
+	}
+}
+
+func TestLoadBytesAndArraysErrors(t *testing.T) {
+	props1 := struct {
+		Key     []byte   `hocon:"path=key"`
+		Salt    [4]byte  `hocon:"path=salt,encoding=hex"`
+		Address [4]int32 `hocon:"path=address"`
+	}{}
+	err1 := LoadConfigText("key: a, salt: 0a0b0c, address: [1, 2, 3, 4]", &props1)
+	assert.EqualError(t, err1, "wrong value for Key [hocon:\"path=key\"] at 1:6: "+
+		"cannot decode base64: illegal base64 data at input byte 0")
+
+	err2 := LoadConfigText("key: aGk, salt: 0a0b0c, address: [1, 2, 3, 4]", &props1)
+	assert.EqualError(t, err2, "wrong value for Salt [hocon:\"path=salt,encoding=hex\"] at 1:17: "+
+		"cannot use 3 bytes as [4]uint8")
+
+	err3 := LoadConfigText("key: [1], salt: 0a0b0c0d, address: [1, 2, 3, 4]", &props1)
+	assert.EqualError(t, err3, "wrong value for Key [hocon:\"path=key\"] at 1:6: cannot use array as bytes")
+
+	err4 := LoadConfigText("key: aGk, salt: 0a0b0c0d, address: [1, 2, 3]", &props1)
+	assert.EqualError(t, err4, "wrong value for Address [hocon:\"path=address\"] at 1:36: "+
+		"cannot use 3 elements as [4]int32")
+
+	props2 := struct {
+		Field1 []byte `hocon:"encoding=base32"`
+	}{}
+	err5 := LoadConfigText("Field1: x", &props2)
+	assert.EqualError(t, err5, "wrong value for Field1 [hocon:\"encoding=base32\"] at 1:9: "+
+		"unknown encoding base32, known ones are base64, hex, plain")
+
+	props3 := struct {
+		Field1 [2]int32 `hocon:"default=[1]"`
+	}{}
+	err6 := LoadConfigText("Field1: [1, 2]", &props3)
+	assert.EqualError(t, err6, "wrong default value for Field1 [hocon:\"default=[1]\"]: "+
+		"cannot use 1 elements as [2]int32")
+}
+
+func TestMergeMode(t *testing.T) {
+	props1 := struct {
+		Name    string
+		Port    int32 `hocon:"default=80"`
+		Debug   bool
+		Hosts   []string
+		Plugins map[string]interface{}
+		DB      struct {
+			User     string
+			Password string
 		}
+	}{Name: "app", Port: 8080, Debug: true, Hosts: []string{"a", "b"}}
+	plugins := map[string]interface{}{
+		"cache": map[string]interface{}{"size": int64(10), "ttl": "1m"},
+		"auth":  "none",
 	}
+	props1.Plugins = plugins
+	props1.DB.User = "admin"
+	props1.DB.Password = "secret"
+	err1 := LoadConfigText("Name: shop, Hosts: [c], Plugins.cache.size: 20, Plugins.log: on, DB.User: shop",
+		&props1, WithMergeMode())
+	if assert.Nil(t, err1) {
+		assert.Equal(t, "shop", props1.Name)
+		assert.Equal(t, int32(8080), props1.Port)
+		assert.Equal(t, true, props1.Debug)
+		assert.Equal(t, []string{"c"}, props1.Hosts)
+		assert.Equal(t, map[string]interface{}{
+			"cache": map[string]interface{}{"size": int64(20), "ttl": "1m"},
+			"auth":  "none",
+			"log":   "on",
+		}, props1.Plugins)
+		assert.Equal(t, "shop", props1.DB.User)
+		assert.Equal(t, "secret", props1.DB.Password)
+		assert.Equal(t, int64(10), plugins["cache"].(map[string]interface{})["size"], "current map must not be modified")
+	}
+
+	props2 := struct {
+		Name string
+	}{Name: "app"}
+	err2 := LoadConfigText("Name: null", &props2, WithMergeMode())
+	if assert.Nil(t, err2) {
+		assert.Equal(t, "app", props2.Name)
+	}
+}
+
+func TestWithoutMergeMode(t *testing.T) {
+	props1 := struct {
+		Port    int32 `hocon:"default=80"`
+		Plugins map[string]interface{}
+	}{Port: 8080, Plugins: map[string]interface{}{"auth": "none"}}
+	err1 := LoadConfigText("Plugins.log: on", &props1)
+	if assert.Nil(t, err1) {
+		assert.Equal(t, int32(80), props1.Port)
+		assert.Equal(t, map[string]interface{}{"log": "on"}, props1.Plugins)
+	}
+}
+
+func TestAlias(t *testing.T) {
+	var records []logRecord
+	props1 := struct {
+		Port int32  `hocon:"path=server.port,alias=http.port,alias=port,deprecated=true"`
+		Host string `hocon:"path=server.host,alias=host"`
+		Old  string `hocon:"default=x,deprecated=true"`
+	}{}
+	err1 := LoadConfigText("port: 80, http.port: 8080, host: localhost", &props1, recordingLogger(LevelWarn, &records))
+	if assert.Nil(t, err1) {
+		assert.Equal(t, int32(8080), props1.Port)
+		assert.Equal(t, "localhost", props1.Host)
+		assert.Equal(t, "x", props1.Old)
+		if assert.Len(t, records, 1) {
+			assert.Equal(t, LevelWarn, records[0].level)
+			assert.Equal(t, "deprecated key http.port is used, use server.port instead", records[0].msg)
+			assert.Equal(t, "Port", records[0].fields["field"])
+			assert.Equal(t, "http.port", records[0].fields["path"])
+		}
+	}
+
+	records = nil
+	err2 := LoadConfigText("server { port: 80, host: h }, http.port: 8080, Old: y", &props1,
+		recordingLogger(LevelWarn, &records))
+	if assert.Nil(t, err2) {
+		assert.Equal(t, int32(80), props1.Port)
+		assert.Equal(t, "h", props1.Host)
+		assert.Equal(t, "y", props1.Old)
+		if assert.Len(t, records, 1) {
+			assert.Equal(t, "deprecated key Old is used", records[0].msg)
+		}
+	}
+
+	err3 := LoadConfigText("host: h", &props1)
+	assert.EqualError(t, err3, "no value either default value provided for Port "+
+		"[hocon:\"path=server.port,alias=http.port,alias=port,deprecated=true\"]")
+}
+
+func TestAliasErrors(t *testing.T) {
+	props1 := struct {
+		Port int32 `hocon:"alias=a..b"`
+	}{}
+	err1 := LoadConfigText("x: 1", &props1)
+	assert.EqualError(t, err1, "tag format error: alias=a..b: wrong alias: 1:1: path has an empty element, "+
+		"use quotes for empty keys")
+
+	props2 := struct {
+		Port int32 `hocon:"deprecated=maybe"`
+	}{}
+	err2 := LoadConfigText("Port: 1", &props2)
+	assertErrRegex(t, err2, `wrong deprecated tag key for Port \[hocon:"deprecated=maybe"\]`)
+}
+
+func TestAliasStruct(t *testing.T) {
+	var records []logRecord
+	var report Report
+	props1 := struct {
+		Server struct {
+			Host string
+			Port int32 `hocon:"default=80"`
+		} `hocon:"node=server,alias=http,alias=legacy.http,deprecated"`
+		DB struct {
+			URL string
+		} `hocon:"deprecated"`
+	}{}
+	err1 := LoadConfigText("legacy.http { Host: old, Port: 8080 }, DB.URL: x", &props1, WithReport(&report),
+		recordingLogger(LevelWarn, &records))
+	if assert.Nil(t, err1) {
+		assert.Equal(t, "old", props1.Server.Host)
+		assert.Equal(t, int32(8080), props1.Server.Port)
+		assert.Equal(t, []string{
+			"warn: deprecated key legacy.http is used, use server instead",
+			"warn: deprecated key DB is used",
+		}, messages(records))
+		assert.Equal(t, "legacy.http.Host", report.Entries[0].Path)
+	}
+
+	records = nil
+	props1.Server.Port = 0
+	err2 := LoadConfigText("server.Host: new, http.Host: old, DB.URL: x", &props1, recordingLogger(LevelWarn, &records))
+	if assert.Nil(t, err2) {
+		assert.Equal(t, "new", props1.Server.Host)
+		assert.Equal(t, int32(80), props1.Server.Port)
+		assert.Equal(t, []string{"warn: deprecated key DB is used"}, messages(records))
+	}
+
+	err3 := LoadConfigText("DB.URL: x", &props1)
+	assert.EqualError(t, err3, "no value either default value provided for Host []")
+}
+
+func TestLogger(t *testing.T) {
+	var records []logRecord
+	props1 := struct {
+		Name    string `hocon:"default=app"`
+		Port    int32  `hocon:"default=80"`
+		Ratio   float32
+		Scale   float32
+		Plugins map[string]interface{}
+		DB      struct {
+			User string
+		}
+	}{}
+	err1 := LoadConfigText(`
+Ratio: 0.1
+Scale: 3.14159265358979
+Plugins { cache.size: 10 }
+DB { User: u, Pasword: p }
+Extra: [1, 2]
+Empty {}
+profiles.prod.Name: shop
+`, &props1, recordingLogger(LevelDebug, &records))
+	if assert.Nil(t, err1) {
+		assert.Equal(t, []string{
+			"info: default value app is used for Name",
+			"info: default value 80 is used for Port",
+			"warn: value 3.14159265358979 of Scale loses precision as float32",
+			"warn: unknown key DB.Pasword",
+			"warn: unknown key Extra",
+			"warn: unknown key Empty",
+			"warn: unknown key profiles.prod.Name",
+		}, messages(records))
+		assert.Equal(t, map[string]interface{}{"field": "Port", "path": "Port", "default": "80"}, records[1].fields)
+		assert.Equal(t, "DB.Pasword", records[3].fields["path"])
+		assert.Equal(t, Origin{Line: 5, Column: 24}, records[3].fields["origin"])
+	}
+
+	// profile sections are known if profiles are active
+	records = nil
+	props2 := struct {
+		Name string
+	}{}
+	err2 := LoadConfigText("Name: app, profiles.prod.Name: shop", &props2, WithProfiles("test"),
+		recordingLogger(LevelWarn, &records))
+	if assert.Nil(t, err2) {
+		assert.Empty(t, records)
+	}
+}
+
+func TestLoggerPanic(t *testing.T) {
+	var records []logRecord
+	assert.Panics(t, func() {
+		_ = LoadConfigText("", nil, recordingLogger(LevelDebug, &records))
+	})
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, LevelError, records[0].level)
+		assert.Equal(t, "cannot parse config: panic", records[0].msg)
+	}
+}
+
+func TestLoggerSecrets(t *testing.T) {
+	var records []logRecord
+	props1 := struct {
+		Password string  `hocon:"secret,default=hunter2"`
+		Token    Secret  `hocon:"default=t0ken"`
+		Salt     float32 `hocon:"secret"`
+	}{}
+	err1 := LoadConfigText(`Salt: 3.14159265358979`, &props1, recordingLogger(LevelDebug, &records))
+	if assert.Nil(t, err1) {
+		assert.Equal(t, []string{
+			"info: default value ****** is used for Password",
+			"info: default value ****** is used for Token",
+			"warn: value ****** of Salt loses precision as float32",
+		}, messages(records))
+		assert.Equal(t, "******", records[0].fields["default"])
+		assert.Equal(t, "******", records[2].fields["value"])
+		assert.Equal(t, "******", records[2].fields["float32"])
+	}
+}
+
+func TestProfilesFile(t *testing.T) {
+	props1 := struct {
+		Name string `hocon:"path=app.name"`
+		URL  string `hocon:"path=app.url"`
+		Port int32  `hocon:"path=app.port"`
+	}{}
+	err1 := LoadConfigFile("tests/profiles/application.conf", &props1)
+	if assert.Nil(t, err1) {
+		assert.Equal(t, "shop", props1.Name)
+		assert.Equal(t, "http://localhost:8080", props1.URL)
+		assert.Equal(t, int32(8080), props1.Port)
+	}
+
+	err2 := LoadConfigFile("tests/profiles/application.conf", &props1, WithProfiles("dev"))
+	if assert.Nil(t, err2) {
+		assert.Equal(t, "http://localhost:8081", props1.URL)
+		assert.Equal(t, int32(8081), props1.Port)
+	}
+
+	// profile file takes precedence over the section of the same profile
+	err3 := LoadConfigFile("tests/profiles/application.conf", &props1, WithProfiles("prod"))
+	if assert.Nil(t, err3) {
+		assert.Equal(t, "http://shop.example.com:443", props1.URL)
+		assert.Equal(t, int32(443), props1.Port)
+	}
+
+	// later profiles take precedence over earlier ones
+	err4 := LoadConfigFile("tests/profiles/application.conf", &props1, WithProfiles("prod", "dev"))
+	if assert.Nil(t, err4) {
+		assert.Equal(t, "http://shop.example.com:8081", props1.URL)
+		assert.Equal(t, int32(8081), props1.Port)
+	}
+
+	config5, err5 := ParseConfigFile("tests/profiles/application.conf", WithProfiles("prod"))
+	if assert.Nil(t, err5) {
+		origin, found := config5.Origin("app.port")
+		assert.True(t, found)
+		assert.Equal(t, Origin{Filename: "tests/profiles/application-prod.conf", Line: 1, Column: 12}, origin)
+	}
+
+	assert.Equal(t, "conf/app-dev.conf", profileFilename("conf/app.conf", "dev"))
+	assert.Equal(t, "app-dev", profileFilename("app", "dev"))
+}
+
+func TestProfilesSelfReferences(t *testing.T) {
+	props1 := struct {
+		Hosts []string `hocon:"path=hosts"`
+		Tags  []string `hocon:"path=tags"`
+		Path  string   `hocon:"path=path"`
+		Extra []string `hocon:"path=extra,optional"`
+		Twice []int32  `hocon:"path=twice,optional"`
+	}{}
+	err1 := LoadConfigFile("tests/profiles/selfref.conf", &props1, WithProfiles("section"))
+	if assert.Nil(t, err1) {
+		assert.Equal(t, []string{"a", "b"}, props1.Hosts)
+		assert.Equal(t, []string{"x", "y"}, props1.Tags)
+		assert.Equal(t, "/usr/bin:/bin", props1.Path)
+		assert.Equal(t, []string{"e"}, props1.Extra)
+		assert.Equal(t, []int32{1, 2}, props1.Twice)
+	}
+
+	props1.Extra, props1.Twice = nil, nil
+	err2 := LoadConfigFile("tests/profiles/selfref.conf", &props1, WithProfiles("file"))
+	if assert.Nil(t, err2) {
+		assert.Equal(t, []string{"a", "c"}, props1.Hosts)
+		assert.Equal(t, []string{"x", "z"}, props1.Tags)
+		assert.Equal(t, "/usr/bin", props1.Path)
+		assert.Nil(t, props1.Extra)
+		assert.Nil(t, props1.Twice)
+	}
+
+	err3 := LoadConfigFile("tests/profiles/selfref.conf", &props1, WithProfiles("section", "file"))
+	if assert.Nil(t, err3) {
+		assert.Equal(t, []string{"a", "b", "c"}, props1.Hosts)
+		assert.Equal(t, []string{"x", "y", "z"}, props1.Tags)
+	}
+
+	props4 := struct{ A []int32 }{}
+	err4 := LoadConfigText("A = [1], profiles.p { A += 2, A += 3 }", &props4, WithProfiles("p"))
+	if assert.Nil(t, err4) {
+		assert.Equal(t, []int32{1, 2, 3}, props4.A)
+	}
+
+	err5 := LoadConfigText("A = [1], profiles.p.A = ${A} [2]", &props4)
+	if assert.Nil(t, err5) {
+		assert.Equal(t, []int32{1}, props4.A, "inactive profile")
+	}
+
+	// without active profiles the profiles key is an ordinary one
+	props6 := struct {
+		Roles   []string
+		Profile []string `hocon:"path=profiles.admin.Roles"`
+	}{}
+	err6 := LoadConfigText("Roles = [x], profiles.admin.Roles = [a], "+
+		"profiles.admin.Roles = ${profiles.admin.Roles} [b]", &props6)
+	if assert.Nil(t, err6) {
+		assert.Equal(t, []string{"a", "b"}, props6.Profile)
+	}
+
+	err7 := LoadConfigText("Roles = [x], profiles.admin.Roles += b", &props6)
+	if assert.Nil(t, err7) {
+		assert.Equal(t, []string{"x"}, props6.Roles)
+		assert.Equal(t, []string{"b"}, props6.Profile)
+	}
+
+	err8 := LoadConfigText("Roles = [x], profiles.admin.Roles += b", &props6, WithProfiles("admin"))
+	if assert.Nil(t, err8) {
+		assert.Equal(t, []string{"x", "b"}, props6.Roles)
+	}
+
+	_, err9 := ParseConfigText("profiles.p { A = ${A} [2] }", WithProfiles("p"))
+	assert.EqualError(t, err9, "1:18: could not resolve substitution ${A} to a value")
+}
+
+func TestProfilesText(t *testing.T) {
+	props1 := struct{ A, B string }{}
+	err1 := LoadConfigText("A: base, B: ${A}, profiles { test.A: test, unused.B: unused }", &props1,
+		WithProfiles("missing", "test"))
+	if assert.Nil(t, err1) {
+		assert.Equal(t, "test", props1.A)
+		assert.Equal(t, "test", props1.B)
+	}
+
+	_, err2 := ParseConfigText("A: 1, profiles.test: 2", WithProfiles("test"))
+	assert.EqualError(t, err2, "1:22: profile test must be an object, not number")
+
+	_, err3 := ParseConfigText("A: 1", WithProfiles(""))
+	assert.EqualError(t, err3, "profile name cannot be empty")
+
+	_, err4 := ParseConfigFile("tests/profiles/application.conf", WithProfiles("broken"))
+	assert.EqualError(t, err4, "tests/profiles/application-broken.conf:1:12: unclosed array, expected ']'")
+}
+
+func TestLoadConfigDir(t *testing.T) {
+	props1 := struct {
+		Name string `hocon:"path=app.name"`
+		Port int32  `hocon:"path=app.port"`
+		URL  string `hocon:"path=app.url"`
+	}{}
+	err1 := LoadConfigDir("tests/conf.d", &props1)
+	if assert.Nil(t, err1) {
+		assert.Equal(t, "shop", props1.Name)
+		assert.Equal(t, int32(9090), props1.Port)
+		assert.Equal(t, "http://localhost:9090", props1.URL)
+	}
+
+	err2 := LoadConfigDir("tests/conf.d", &props1, WithJSONFiles())
+	if assert.Nil(t, err2) {
+		assert.Equal(t, "from json", props1.Name)
+		assert.Equal(t, int32(9090), props1.Port)
+	}
+
+	config3, err3 := ParseConfigDir("tests/conf.d")
+	if assert.Nil(t, err3) {
+		origin, found := config3.Origin("app.port")
+		assert.True(t, found)
+		assert.Equal(t, Origin{Filename: "tests/conf.d/20-override.conf", Line: 1, Column: 12}, origin)
+	}
+
+	err4 := LoadConfigDir("tests/conf.d.broken", &props1)
+	assert.EqualError(t, err4, "tests/conf.d.broken/20-bad.conf:2:5: unclosed object, expected '}'")
+
+	err5 := LoadConfigDir("tests/nonexistent", &props1)
+	assertErrRegex(t, err5, "^cannot read configuration directory: open tests/nonexistent: no such file or directory$")
+}
+
+func TestLoadConfigDirSelfReferences(t *testing.T) {
+	props1 := struct {
+		Hosts   []string
+		Tags    []string
+		Path    string
+		DB      map[string]interface{}
+		Missing []string
+	}{}
+	err1 := LoadConfigDir("tests/conf.d.selfref", &props1, WithNaming(LowerCamelCase))
+	if assert.Nil(t, err1) {
+		assert.Equal(t, []string{"a", "b"}, props1.Hosts)
+		assert.Equal(t, []string{"x", "y"}, props1.Tags)
+		assert.Equal(t, "/usr/bin:/bin", props1.Path)
+		assert.Equal(t, map[string]interface{}{"port": int64(5432), "host": "localhost"}, props1.DB)
+		assert.Equal(t, []string{"z"}, props1.Missing)
+	}
+}
+
+func TestResolvers(t *testing.T) {
+	secrets := MapResolver{"db/password": "s3cr3t"}
+	cmdout := ResolverFunc(func(argument string) (string, bool, error) {
+		if argument == "git describe" {
+			return "v1.2.3", true, nil
+		}
+		return "", false, nil
+	})
+	failing := ResolverFunc(func(string) (string, bool, error) {
+		return "", false, errors.New("vault is sealed")
+	})
+
+	var report Report
+	props1 := struct {
+		Password string `hocon:"path=db.password"`
+		Token    string `hocon:"path=api.token,default=none"`
+		Version  string
+	}{}
+	err1 := LoadConfigText("db.password: ${secret:db/password}, api.token: ${?secret:api/token}, "+
+		"Version: ${cmdout:git describe}", &props1, WithResolver("secret", secrets), WithResolver("cmdout", cmdout),
+		WithReport(&report))
+	if assert.Nil(t, err1) {
+		assert.Equal(t, "s3cr3t", props1.Password)
+		assert.Equal(t, "none", props1.Token)
+		assert.Equal(t, "v1.2.3", props1.Version)
+		assert.Equal(t, SourceResolver, report.Entries[0].Source)
+		assert.Equal(t, "resolver", SourceResolver.String())
+	}
+
+	props2 := struct{ A string }{}
+	err2 := LoadConfigText("A: ${file:/run/secrets/a}", &props2,
+		WithResolver("file", MapResolver{"/run/secrets/a": "fake"}))
+	if assert.Nil(t, err2) {
+		assert.Equal(t, "fake", props2.A)
+	}
+
+	_, err3 := ParseConfigText("a: ${?secret:a}", WithResolver("secret", failing))
+	assert.EqualError(t, err3, "1:4: cannot resolve substitution ${secret:a}: vault is sealed")
+
+	_, err4 := ParseConfigText("a: ${secret:a}", WithResolver("secret", MapResolver{}))
+	assert.EqualError(t, err4, "1:4: could not resolve substitution ${secret:a} to a value")
+
+	_, err5 := ParseConfigText("a: ${secret:a}")
+	assert.EqualError(t, err5, "1:4: unknown resolver secret in substitution")
+}
+
+func TestResolverBeforeConfigAndEnv(t *testing.T) {
+	assert.Nil(t, os.Setenv("secret", "from env"))
+	defer func() {
+		_ = os.Unsetenv("secret")
+	}()
+
+	config1, err1 := ParseConfigText("secret: from config, A: ${secret:a}", WithResolver("secret", MapResolver{"a": "x"}))
+	if assert.Nil(t, err1) {
+		props1 := struct{ A string }{}
+		assert.Nil(t, config1.Decode(&props1))
+		assert.Equal(t, "x", props1.A)
+
+		origin, found := config1.Origin("A")
+		assert.True(t, found)
+		assert.Equal(t, Origin{Line: 1, Column: 25, Resolver: "secret:a"}, origin)
+		assert.Equal(t, "resolver secret:a at 1:25", origin.String())
+	}
+}
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func encrypted(t *testing.T, value string) string {
+	result, err := Encrypt(value, testKey)
+	assert.Nil(t, err)
+	return result
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	encrypted1, err1 := Encrypt("s3cr3t", testKey)
+	if assert.Nil(t, err1) {
+		assert.Regexp(t, "^ENC\\([A-Za-z0-9_-]+\\)$", encrypted1)
+		decrypted, err := decrypt(encrypted1, testKey)
+		assert.Nil(t, err)
+		assert.Equal(t, "s3cr3t", decrypted)
+		assert.NotEqual(t, encrypted1, encrypted(t, "s3cr3t"), "nonce must be random")
+	}
+
+	_, err2 := Encrypt("x", []byte("short"))
+	assertErrRegex(t, err2, "^wrong encryption key")
+
+	key3, err3 := ParseEncryptionKey(base64.StdEncoding.EncodeToString(testKey) + "\n")
+	if assert.Nil(t, err3) {
+		assert.Equal(t, testKey, key3)
+	}
+
+	_, err4 := ParseEncryptionKey("!!!")
+	assertErrRegex(t, err4, "^malformed encryption key")
+}
+
+func TestLoadEncrypted(t *testing.T) {
+	props1 := struct {
+		Password string
+		Port     int32
+		Hosts    []string
+		Plain    string
+	}{}
+	err1 := LoadConfigText("Password: "+encrypted(t, "s3cr3t")+", Port: \""+encrypted(t, "8080")+"\", Hosts: [a, "+
+		encrypted(t, "db.local")+"], Plain: ENC", &props1, WithEncryptionKey(testKey))
+	if assert.Nil(t, err1) {
+		assert.Equal(t, "s3cr3t", props1.Password)
+		assert.Equal(t, int32(8080), props1.Port)
+		assert.Equal(t, []string{"a", "db.local"}, props1.Hosts)
+		assert.Equal(t, "ENC", props1.Plain)
+	}
+
+	token := encrypted(t, "t0ken")
+	props2 := struct {
+		Plugins map[string]interface{}
+		Any     interface{}
+		Hosts   []string
+		Ports   [2]int32 `hocon:"sep=;"`
+	}{}
+	err2 := LoadConfigText("Plugins { auth { token: "+token+", list: [x, "+token+"] } }, Any: "+token+
+		", Hosts: \""+encrypted(t, "a")+", "+encrypted(t, "b")+"\", Ports: \""+encrypted(t, "80")+";"+
+		encrypted(t, "81")+"\"", &props2, WithEncryptionKey(testKey))
+	if assert.Nil(t, err2) {
+		assert.Equal(t, map[string]interface{}{"auth": map[string]interface{}{"token": "t0ken",
+			"list": []interface{}{"x", "t0ken"}}}, props2.Plugins)
+		assert.Equal(t, "t0ken", props2.Any)
+		assert.Equal(t, []string{"a", "b"}, props2.Hosts)
+		assert.Equal(t, [2]int32{80, 81}, props2.Ports)
+	}
+
+	err3 := LoadConfigText("Plugins { a: "+token+" }, Any: x, Hosts: [], Ports: [1, 2]", &props2)
+	assertErrRegex(t, err3, "^wrong value for Plugins \\[\\] at 1:9: no encryption key to decrypt value")
+
+	err4 := LoadConfigText("Plugins {}, Any: x, Hosts: \"a, ENC(!)\", Ports: [1, 2]", &props2,
+		WithEncryptionKey(testKey))
+	assertErrRegex(t, err4, "^wrong value for Hosts \\[\\] at 1:28: malformed encrypted value")
+}
+
+func TestLoadEncryptedKeyFromEnv(t *testing.T) {
+	assert.Nil(t, os.Setenv(EncryptionKeyEnv, base64.StdEncoding.EncodeToString(testKey)))
+	defer func() {
+		_ = os.Unsetenv(EncryptionKeyEnv)
+	}()
+
+	props1 := struct{ A string }{}
+	err1 := LoadConfigText("A: "+encrypted(t, "s3cr3t"), &props1)
+	if assert.Nil(t, err1) {
+		assert.Equal(t, "s3cr3t", props1.A)
+	}
+}
+
+func TestLoadEncryptedErrors(t *testing.T) {
+	value := encrypted(t, "s3cr3t")
+	props1 := struct{ A string }{}
+
+	err1 := LoadConfigText("A: "+value, &props1)
+	assertErrRegex(t, err1, "^wrong value for A \\[\\] at 1:4: no encryption key to decrypt value")
+
+	err2 := LoadConfigText("A: "+value, &props1, WithEncryptionKey([]byte("fedcba9876543210fedcba9876543210")))
+	assertErrRegex(t, err2, "^wrong value for A \\[\\] at 1:4: cannot decrypt value: "+
+		"cipher: message authentication failed")
+
+	err3 := LoadConfigText("A: \"ENC(!)\"", &props1, WithEncryptionKey(testKey))
+	assertErrRegex(t, err3, "^wrong value for A \\[\\] at 1:4: malformed encrypted value")
+
+	err4 := LoadConfigText("A: ENC(AAAA)", &props1, WithEncryptionKey(testKey))
+	assertErrRegex(t, err4, "malformed encrypted value: too short$")
 }
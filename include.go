@@ -0,0 +1,276 @@
+package hocon
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IncludeResolver reads configurations named by include directives. Name is a file path or URL which is
+// already made relative to the including configuration. Resolver must return an error matching fs.ErrNotExist
+// (checked by errors.Is) if there is no such configuration, such includes are skipped unless they are required.
+type IncludeResolver interface {
+	ReadInclude(name string) ([]byte, error)
+}
+
+// OSIncludeResolver reads included files from the file system, it is used for files by default.
+type OSIncludeResolver struct{}
+
+// ReadInclude reads the file.
+func (OSIncludeResolver) ReadInclude(name string) ([]byte, error) {
+	return ioutil.ReadFile(name)
+}
+
+// FSIncludeResolver reads included files from the file system like embed.FS. Names are taken relative to the
+// root of the file system.
+type FSIncludeResolver struct {
+	FS fs.FS
+}
+
+// ReadInclude reads the file from FS.
+func (resolver FSIncludeResolver) ReadInclude(name string) ([]byte, error) {
+	return fs.ReadFile(resolver.FS, strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/"))
+}
+
+// MapIncludeResolver keeps included configurations in memory by their names, it is handy for tests.
+type MapIncludeResolver map[string]string
+
+// ReadInclude returns the configuration stored by the name.
+func (m MapIncludeResolver) ReadInclude(name string) ([]byte, error) {
+	content, ok := m[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+	}
+	return []byte(content), nil
+}
+
+// HTTPIncludeResolver downloads included configurations with the client, it is used for URLs by default.
+// Nil client means http.DefaultClient. Not found status means there is no such configuration.
+type HTTPIncludeResolver struct {
+	Client *http.Client
+}
+
+// ReadInclude downloads the configuration.
+func (resolver HTTPIncludeResolver) ReadInclude(name string) ([]byte, error) {
+	client := resolver.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	switch {
+	case response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone:
+		return nil, fmt.Errorf("%s: %s: %w", name, response.Status, fs.ErrNotExist)
+	case response.StatusCode < 200 || response.StatusCode > 299:
+		return nil, fmt.Errorf("%s: %s", name, response.Status)
+	}
+	return ioutil.ReadAll(response.Body)
+}
+
+// WithIncludeResolver sets the resolver for `include "name"` and `include file("name")` directives.
+func WithIncludeResolver(resolver IncludeResolver) Option {
+	return func(opts *options) {
+		opts.fileIncludes = resolver
+	}
+}
+
+// WithURLIncludeResolver sets the resolver for `include url("name")` directives and for `include "name"` ones
+// found in configurations which are included by URL themselves.
+func WithURLIncludeResolver(resolver IncludeResolver) Option {
+	return func(opts *options) {
+		opts.urlIncludes = resolver
+	}
+}
+
+// includeKind tells how the name of included configuration is interpreted.
+type includeKind int
+
+const (
+	// includeHeuristic is `include "name"`, it is a URL if the including configuration is a URL and
+	// a file otherwise. Extensions .conf and .json are tried if the name has no extension.
+	includeHeuristic includeKind = iota
+	includeFile
+	includeURL
+	includeClasspath
+)
+
+var includeFunctions = map[string]includeKind{
+	"":           includeHeuristic,
+	"file(":      includeFile,
+	"url(":       includeURL,
+	"classpath(": includeClasspath,
+}
+
+// includeSpec is a parsed include directive like `include required(file("name"))`.
+type includeSpec struct {
+	kind     includeKind
+	name     string
+	required bool
+}
+
+// parseIncludeSpec makes include spec from the text surrounding quoted name, like `required(file(` and `))`.
+func parseIncludeSpec(origin Origin, opener string, name string, closer string) (includeSpec, error) {
+	spec := includeSpec{name: name}
+	function := opener
+	if strings.HasPrefix(function, "required(") {
+		spec.required = true
+		function = strings.TrimPrefix(function, "required(")
+	}
+	kind, ok := includeFunctions[function]
+	if !ok {
+		return spec, newParseError(origin, "unknown include function %s", opener)
+	}
+	if closer != strings.Repeat(")", strings.Count(opener, "(")) {
+		return spec, newParseError(origin, "unbalanced parentheses in include directive")
+	}
+	spec.kind = kind
+	return spec, nil
+}
+
+// includer reads and parses included configurations. Stack keeps the names of configurations which are being
+// parsed at the moment to detect include cycles.
+type includer struct {
+	files IncludeResolver
+	urls  IncludeResolver
+	stack []string
+}
+
+func newIncluder(opts *options) *includer {
+	return &includer{files: opts.fileIncludes, urls: opts.urlIncludes}
+}
+
+// include reads and parses the configuration named by the spec. From is the origin of the include directive,
+// its filename is the including configuration. It returns nil if the configuration does not exist and it is
+// not required.
+func (inc *includer) include(spec includeSpec, from Origin) (*objectValue, error) {
+	if spec.kind == includeClasspath {
+		return nil, newParseError(from, "classpath includes are not supported")
+	}
+
+	resolver, name, err := inc.locate(spec, from.Filename)
+	if err != nil {
+		return nil, newParseError(from, "cannot include %s: %s", spec.name, err)
+	}
+	candidates := []string{name}
+	if spec.kind == includeHeuristic && path.Ext(name) == "" {
+		// .conf takes precedence over .json
+		candidates = []string{name + ".json", name + ".conf"}
+	}
+
+	var result *objectValue
+	for _, candidate := range candidates {
+		for _, parent := range inc.stack {
+			if parent == candidate {
+				return nil, newParseError(from, "include cycle: %s -> %s", strings.Join(inc.stack, " -> "),
+					candidate)
+			}
+		}
+
+		content, err := resolver.ReadInclude(candidate)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, newParseError(from, "cannot include %s: %s", spec.name, err)
+		}
+		included, err := parse(candidate, string(content), inc, &from, nil)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = included
+		} else {
+			result = mergeValues(result, included).(*objectValue)
+		}
+	}
+
+	if result == nil && spec.required {
+		return nil, newParseError(from, "cannot include %s: required configuration does not exist", spec.name)
+	}
+	return result, nil
+}
+
+// locate returns the resolver and the name of included configuration relative to the including one.
+func (inc *includer) locate(spec includeSpec, including string) (IncludeResolver, string, error) {
+	base, baseIsURL := parseURL(including)
+	switch {
+	case spec.kind == includeURL, spec.kind == includeHeuristic && baseIsURL:
+		target, err := url.Parse(spec.name)
+		if err != nil {
+			return nil, "", err
+		}
+		if baseIsURL {
+			target = base.ResolveReference(target)
+		}
+		if !target.IsAbs() {
+			return nil, "", errors.New("url must be absolute")
+		}
+		return inc.urls, target.String(), nil
+	}
+
+	name := spec.name
+	if !filepath.IsAbs(name) && including != "" && !baseIsURL {
+		name = filepath.Join(filepath.Dir(including), name)
+	}
+	return inc.files, name, nil
+}
+
+// parseURL parses the name of configuration and tells if it is an absolute URL rather than a file name.
+func parseURL(name string) (*url.URL, bool) {
+	parsed, err := url.Parse(name)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, false
+	}
+	return parsed, true
+}
+
+// relativize adds the prefix to the paths of substitutions in included configuration. The substitutions are
+// looked up by the full path first and then by the original one.
+func relativize(v value, prefix []string) value {
+	if len(prefix) == 0 {
+		return v
+	}
+	switch typed := v.(type) {
+	case *objectValue:
+		obj := newObject(typed.Origin)
+		for _, key := range typed.keys {
+			obj.set(key, relativize(typed.fields[key], prefix))
+		}
+		return obj
+	case *arrayValue:
+		return &arrayValue{Origin: typed.Origin, elements: relativizeAll(typed.elements, prefix)}
+	case *concatValue:
+		return &concatValue{Origin: typed.Origin, parts: relativizeAll(typed.parts, prefix)}
+	case *mergeValue:
+		return &mergeValue{Origin: typed.Origin, parts: relativizeAll(typed.parts, prefix)}
+	case *substValue:
+		if typed.resolver != "" || typed.envOnly {
+			return typed
+		}
+		subst := *typed
+		subst.path = append(append([]string{}, prefix...), typed.path...)
+		subst.prefixLen = typed.prefixLen + len(prefix)
+		return &subst
+	}
+	return v
+}
+
+func relativizeAll(values []value, prefix []string) []value {
+	result := make([]value, 0, len(values))
+	for _, v := range values {
+		result = append(result, relativize(v, prefix))
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package hocon
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type includeProperties struct {
+	Name    string `hocon:"path=app.name"`
+	Port    int32  `hocon:"path=app.port"`
+	Timeout int32  `hocon:"path=app.timeout"`
+	URL     string `hocon:"path=app.db.url"`
+}
+
+func TestIncludeFiles(t *testing.T) {
+	var props includeProperties
+	if assert.Nil(t, LoadConfigFile("tests/include/application.conf", &props)) {
+		assert.Equal(t, includeProperties{Name: "shop", Port: 5432, Timeout: 30, URL: "postgres://localhost:5432"},
+			props)
+	}
+}
+
+func TestIncludeOrigin(t *testing.T) {
+	config, err := ParseConfigFile("tests/include/application.conf")
+	if assert.Nil(t, err) {
+		origin, found := config.Origin("app.db.host")
+		assert.True(t, found)
+		assert.Equal(t, "tests/include/sub/db.conf:2:10, included from tests/include/application.conf:4:3",
+			origin.String())
+	}
+}
+
+func TestIncludeMap(t *testing.T) {
+	resolver := MapIncludeResolver{
+		"base.conf":         "a = 1, b = ${a}, nested { include \"conf/nested.conf\" }",
+		"conf/nested.conf":  "x = ${a}, y = ${HOCON_INCLUDE_TEST}, include \"deeper.conf\"",
+		"conf/deeper.conf":  "z = deep",
+		"conf/ignored.json": "{}",
+	}
+	assert.Nil(t, os.Setenv("HOCON_INCLUDE_TEST", "env"))
+	defer func() {
+		_ = os.Unsetenv("HOCON_INCLUDE_TEST")
+	}()
+
+	config, err := ParseConfigText(`include "base.conf", include "missing", a = 2`, WithIncludeResolver(resolver))
+	if assert.Nil(t, err) {
+		assert.Equal(t, map[string]interface{}{
+			"a": "2",
+			"b": "2",
+			"nested": map[string]interface{}{
+				"x": "2",
+				"y": "env",
+				"z": "deep",
+			},
+		}, plain(config.root))
+	}
+}
+
+func TestIncludeRelativeSubstitutions(t *testing.T) {
+	resolver := MapIncludeResolver{"part.conf": "x = 1, y = ${x}"}
+	config, err := ParseConfigText(`x = root, a { include "part.conf" }, b = ${a.y}`, WithIncludeResolver(resolver))
+	if assert.Nil(t, err) {
+		assert.Equal(t, map[string]interface{}{
+			"x": "root",
+			"a": map[string]interface{}{"x": "1", "y": "1"},
+			"b": "1",
+		}, plain(config.root))
+	}
+}
+
+func TestIncludeFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/app.conf":   {Data: []byte(`include "part"`)},
+		"conf/part.conf":  {Data: []byte("A = from fs")},
+		"conf/other.conf": {Data: []byte("A = other")},
+	}
+	var receiver struct{ A string }
+	if assert.Nil(t, LoadConfigText(`include required("/conf/app.conf")`, &receiver,
+		WithIncludeResolver(FSIncludeResolver{FS: fsys}))) {
+		assert.Equal(t, "from fs", receiver.A)
+	}
+}
+
+func TestIncludeURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/conf/app.conf":
+			_, _ = fmt.Fprint(w, `A = from http, include "common.conf"`)
+		case "/conf/common.conf":
+			_, _ = fmt.Fprint(w, "B = common")
+		case "/broken.conf":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	opts := WithURLIncludeResolver(HTTPIncludeResolver{Client: server.Client()})
+
+	var receiver struct{ A, B string }
+	err := LoadConfigText(`include url("`+server.URL+`/conf/app.conf"), include url("`+server.URL+`/missing.conf")`,
+		&receiver, opts)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "from http", receiver.A)
+		assert.Equal(t, "common", receiver.B)
+	}
+
+	_, err = ParseConfigText(`include required(url("`+server.URL+`/missing.conf"))`, opts)
+	assert.EqualError(t, err, "1:1: cannot include "+server.URL+"/missing.conf: required configuration does not exist")
+
+	_, err = ParseConfigText(`include url("`+server.URL+`/broken.conf")`, opts)
+	assert.EqualError(t, err, "1:1: cannot include "+server.URL+"/broken.conf: "+server.URL+
+		"/broken.conf: 500 Internal Server Error")
+}
+
+func TestIncludeErrors(t *testing.T) {
+	_, err := ParseConfigFile("tests/include/cycle-a.conf")
+	assert.EqualError(t, err, "tests/include/cycle-b.conf:1:1, included from tests/include/cycle-a.conf:2:1: "+
+		"include cycle: tests/include/cycle-a.conf -> tests/include/cycle-b.conf -> tests/include/cycle-a.conf")
+
+	resolver := WithIncludeResolver(MapIncludeResolver{"bad.conf": "a = ["})
+	_, err = ParseConfigText(`include "bad.conf"`, resolver)
+	assert.EqualError(t, err, "bad.conf:1:5, included from 1:1: unclosed array, expected ']'")
+
+	_, err = ParseConfigText(`include required("missing")`, resolver)
+	assert.EqualError(t, err, "1:1: cannot include missing: required configuration does not exist")
+
+	_, err = ParseConfigText(`include classpath("x.conf")`, resolver)
+	assert.EqualError(t, err, "1:1: classpath includes are not supported")
+
+	_, err = ParseConfigText(`include http("x.conf")`, resolver)
+	assert.EqualError(t, err, "1:1: unknown include function http(")
+
+	_, err = ParseConfigText(`include file("x.conf"`, resolver)
+	assert.EqualError(t, err, "1:1: unbalanced parentheses in include directive")
+
+	_, err = ParseConfigText(`include url("relative.conf")`, resolver)
+	assert.EqualError(t, err, "1:1: cannot include relative.conf: url must be absolute")
+}
+
+func TestIncludeKey(t *testing.T) {
+	assertParsed(t, "include = 1, x { include { a = 2 } }", map[string]interface{}{
+		"include": "1",
+		"x":       map[string]interface{}{"include": map[string]interface{}{"a": "2"}},
+	})
+}
@@ -0,0 +1,457 @@
+package hocon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// forbiddenChars cannot be used in unquoted strings.
+const forbiddenChars = "$\"{}[]:=,+#`^?!@*&\\"
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenNewline
+	tokenWhitespace
+	tokenComma
+	tokenColon
+	tokenEquals
+	tokenPlusEquals
+	tokenOpenBrace
+	tokenCloseBrace
+	tokenOpenBracket
+	tokenCloseBracket
+	tokenUnquoted
+	tokenQuoted
+	tokenSubstitution
+)
+
+// token is a lexical unit of HOCON text. Text holds the content of strings and whitespaces, path and optional
+// describe substitutions. Substitutions like ${file:/path} have resolver name and keep its argument in text.
+type token struct {
+	Origin
+	typ      tokenType
+	text     string
+	path     []string
+	optional bool
+	resolver string
+}
+
+// describe returns human readable token description to be used in error messages.
+func (tok token) describe() string {
+	switch tok.typ {
+	case tokenEOF:
+		return "end of input"
+	case tokenNewline:
+		return "newline"
+	case tokenWhitespace:
+		return "whitespace"
+	case tokenUnquoted:
+		return fmt.Sprintf("'%s'", tok.text)
+	case tokenQuoted:
+		return quoteString(tok.text)
+	case tokenSubstitution:
+		return "substitution"
+	}
+	return fmt.Sprintf("'%s'", tok.text)
+}
+
+type lexer struct {
+	input  []rune
+	offset int
+	pos    Origin
+}
+
+func newLexer(filename string, input string) *lexer {
+	return &lexer{input: []rune(input), pos: Origin{Filename: filename, Line: 1, Column: 1}}
+}
+
+// lex splits the whole input to tokens, the last token is always tokenEOF.
+func (l *lexer) lex() ([]token, error) {
+	var tokens []token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.typ == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+// lexPath splits the whole input to tokens which may form a path expression.
+func (l *lexer) lexPath() ([]token, error) {
+	var tokens []token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		switch tok.typ {
+		case tokenEOF:
+			return tokens, nil
+		case tokenUnquoted, tokenQuoted, tokenWhitespace:
+			tokens = append(tokens, tok)
+		default:
+			return nil, newParseError(tok.Origin, "unexpected %s in path expression", tok.describe())
+		}
+	}
+}
+
+func (l *lexer) peek(n int) rune {
+	if l.offset+n >= len(l.input) {
+		return 0
+	}
+	return l.input[l.offset+n]
+}
+
+func (l *lexer) hasPrefix(prefix string) bool {
+	for i, r := range []rune(prefix) {
+		if l.offset+i >= len(l.input) || l.input[l.offset+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *lexer) eof() bool {
+	return l.offset >= len(l.input)
+}
+
+func (l *lexer) advance() rune {
+	r := l.input[l.offset]
+	l.offset++
+	if r == '\n' {
+		l.pos.Line++
+		l.pos.Column = 1
+	} else {
+		l.pos.Column++
+	}
+	return r
+}
+
+func isWhitespace(r rune) bool {
+	return r != '\n' && (unicode.IsSpace(r) || r == '\ufeff' || unicode.Is(unicode.Zs, r))
+}
+
+func (l *lexer) isUnquotedChar() bool {
+	r := l.peek(0)
+	return !l.eof() && r != '\n' && !isWhitespace(r) && !strings.ContainsRune(forbiddenChars, r) &&
+		!l.hasPrefix("//")
+}
+
+// next returns the next token skipping comments.
+func (l *lexer) next() (token, error) {
+	for {
+		start := l.pos
+		if l.eof() {
+			return token{Origin: start, typ: tokenEOF}, nil
+		}
+
+		r := l.peek(0)
+		switch {
+		case r == '\n':
+			l.advance()
+			return token{Origin: start, typ: tokenNewline, text: "\n"}, nil
+
+		case isWhitespace(r):
+			var sb strings.Builder
+			for !l.eof() && isWhitespace(l.peek(0)) {
+				sb.WriteRune(l.advance())
+			}
+			return token{Origin: start, typ: tokenWhitespace, text: sb.String()}, nil
+
+		case r == '#' || l.hasPrefix("//"):
+			for !l.eof() && l.peek(0) != '\n' {
+				l.advance()
+			}
+			continue
+
+		case r == '"':
+			if l.hasPrefix(`"""`) {
+				return l.lexTripleQuoted()
+			}
+			return l.lexQuoted()
+
+		case r == '$':
+			if l.peek(1) != '{' {
+				return token{}, newParseError(start, "'$' is not allowed in unquoted text, use quotes or ${path}")
+			}
+			return l.lexSubstitution()
+
+		case r == '+':
+			if l.peek(1) != '=' {
+				return token{}, newParseError(start, "'+' is not allowed in unquoted text, use quotes or '+='")
+			}
+			l.advance()
+			l.advance()
+			return token{Origin: start, typ: tokenPlusEquals, text: "+="}, nil
+		}
+
+		if typ, ok := punctuation[r]; ok {
+			l.advance()
+			return token{Origin: start, typ: typ, text: string(r)}, nil
+		}
+
+		if strings.ContainsRune(forbiddenChars, r) {
+			return token{}, newParseError(start, "'%c' is not allowed in unquoted text, use quotes", r)
+		}
+
+		var sb strings.Builder
+		for l.isUnquotedChar() {
+			sb.WriteRune(l.advance())
+		}
+		return token{Origin: start, typ: tokenUnquoted, text: sb.String()}, nil
+	}
+}
+
+var punctuation = map[rune]tokenType{
+	',': tokenComma,
+	':': tokenColon,
+	'=': tokenEquals,
+	'{': tokenOpenBrace,
+	'}': tokenCloseBrace,
+	'[': tokenOpenBracket,
+	']': tokenCloseBracket,
+}
+
+// lexQuoted reads JSON-like quoted string with escape sequences.
+func (l *lexer) lexQuoted() (token, error) {
+	start := l.pos
+	l.advance()
+	var sb strings.Builder
+	for {
+		if l.eof() || l.peek(0) == '\n' {
+			return token{}, newParseError(start, "unclosed quoted string")
+		}
+		pos := l.pos
+		r := l.advance()
+		switch r {
+		case '"':
+			return token{Origin: start, typ: tokenQuoted, text: sb.String()}, nil
+		case '\\':
+			escaped, err := l.lexEscape(pos)
+			if err != nil {
+				return token{}, err
+			}
+			sb.WriteRune(escaped)
+		default:
+			if r < 0x20 {
+				return token{}, newParseError(start, "control character %q is not allowed in quoted string", r)
+			}
+			sb.WriteRune(r)
+		}
+	}
+}
+
+// lexEscape reads escape sequence after a backslash located at given position.
+func (l *lexer) lexEscape(pos Origin) (rune, error) {
+	if l.eof() {
+		return 0, newParseError(pos, "unfinished escape sequence")
+	}
+	switch r := l.advance(); r {
+	case '"', '\\', '/':
+		return r, nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		code, err := l.lexUnicode(pos)
+		if err != nil {
+			return 0, err
+		}
+		// characters beyond the basic plane are written as UTF-16 surrogate pairs like `\ud83d\ude00`, lone
+		// surrogates are kept and become replacement characters
+		if utf16.IsSurrogate(code) && l.hasPrefix(`\u`) && l.offset+6 <= len(l.input) {
+			low, err := strconv.ParseUint(string(l.input[l.offset+2:l.offset+6]), 16, 32)
+			if pair := utf16.DecodeRune(code, rune(low)); err == nil && pair != unicode.ReplacementChar {
+				for i := 0; i < 6; i++ {
+					l.advance()
+				}
+				return pair, nil
+			}
+		}
+		return code, nil
+	default:
+		return 0, newParseError(pos, "unknown escape sequence '\\%c'", r)
+	}
+}
+
+// lexUnicode reads four hex digits of unicode escape sequence located at given position.
+func (l *lexer) lexUnicode(pos Origin) (rune, error) {
+	if l.offset+4 > len(l.input) {
+		return 0, newParseError(pos, "unfinished unicode escape sequence")
+	}
+	code, err := strconv.ParseUint(string(l.input[l.offset:l.offset+4]), 16, 32)
+	if err != nil {
+		return 0, newParseError(pos, "malformed unicode escape sequence")
+	}
+	for i := 0; i < 4; i++ {
+		l.advance()
+	}
+	return rune(code), nil
+}
+
+// lexTripleQuoted reads multi-line raw string. Quotes right before closing ones belong to the string.
+func (l *lexer) lexTripleQuoted() (token, error) {
+	start := l.pos
+	for i := 0; i < 3; i++ {
+		l.advance()
+	}
+	var sb strings.Builder
+	for {
+		if l.eof() {
+			return token{}, newParseError(start, "unclosed triple-quoted string")
+		}
+		if l.hasPrefix(`"""`) && l.peek(3) != '"' {
+			for i := 0; i < 3; i++ {
+				l.advance()
+			}
+			return token{Origin: start, typ: tokenQuoted, text: sb.String()}, nil
+		}
+		sb.WriteRune(l.advance())
+	}
+}
+
+// lexSubstitution reads ${path}, ${?path} or ${resolver:argument}.
+func (l *lexer) lexSubstitution() (token, error) {
+	start := l.pos
+	l.advance()
+	l.advance()
+	optional := false
+	if l.peek(0) == '?' {
+		optional = true
+		l.advance()
+	}
+
+	if resolver := l.resolverName(); resolver != "" {
+		for range resolver + ":" {
+			l.advance()
+		}
+		var sb strings.Builder
+		for l.peek(0) != '}' {
+			if l.eof() || l.peek(0) == '\n' {
+				return token{}, newParseError(start, "unclosed substitution")
+			}
+			sb.WriteRune(l.advance())
+		}
+		l.advance()
+		return token{Origin: start, typ: tokenSubstitution, optional: optional, resolver: resolver,
+			text: strings.TrimSpace(sb.String())}, nil
+	}
+
+	var tokens []token
+	for {
+		if l.peek(0) == '}' {
+			l.advance()
+			break
+		}
+		tok, err := l.next()
+		if err != nil {
+			return token{}, err
+		}
+		switch tok.typ {
+		case tokenUnquoted, tokenQuoted, tokenWhitespace:
+			tokens = append(tokens, tok)
+		case tokenEOF, tokenNewline:
+			return token{}, newParseError(start, "unclosed substitution")
+		default:
+			return token{}, newParseError(tok.Origin, "unexpected %s in substitution", tok.describe())
+		}
+	}
+
+	path, err := pathFromTokens(tokens)
+	if err != nil {
+		return token{}, err
+	}
+	if path == nil {
+		return token{}, newParseError(start, "empty substitution")
+	}
+	return token{Origin: start, typ: tokenSubstitution, path: path, optional: optional}, nil
+}
+
+// resolverName returns the name of the resolver if the substitution starts with `name:`. Colon cannot be a part
+// of unquoted path so there is no ambiguity.
+func (l *lexer) resolverName() string {
+	var sb strings.Builder
+	for i := 0; ; i++ {
+		r := l.peek(i)
+		switch {
+		case r == ':' && i > 0:
+			return sb.String()
+		case unicode.IsLetter(r), i > 0 && (unicode.IsDigit(r) || r == '-' || r == '_'):
+			sb.WriteRune(r)
+		default:
+			return ""
+		}
+	}
+}
+
+// pathFromTokens builds path from unquoted, quoted and whitespace tokens. Unquoted text is split by dots,
+// quoted strings are taken as is. Leading and trailing whitespaces are ignored. It returns nil for empty
+// list of tokens.
+func pathFromTokens(tokens []token) ([]string, error) {
+	tokens = trimWhitespaceTokens(tokens)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	var path []string
+	var element strings.Builder
+	quoted := false
+	flush := func(pos Origin) error {
+		if element.Len() == 0 && !quoted {
+			return newParseError(pos, "path has an empty element, use quotes for empty keys")
+		}
+		path = append(path, element.String())
+		element.Reset()
+		quoted = false
+		return nil
+	}
+
+	for _, tok := range tokens {
+		switch tok.typ {
+		case tokenQuoted:
+			element.WriteString(tok.text)
+			quoted = true
+		case tokenUnquoted:
+			pieces := strings.Split(tok.text, ".")
+			element.WriteString(pieces[0])
+			for _, piece := range pieces[1:] {
+				if err := flush(tok.Origin); err != nil {
+					return nil, err
+				}
+				element.WriteString(piece)
+			}
+		default:
+			element.WriteString(tok.text)
+		}
+	}
+	if err := flush(tokens[len(tokens)-1].Origin); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+func trimWhitespaceTokens(tokens []token) []token {
+	for len(tokens) > 0 && tokens[0].typ == tokenWhitespace {
+		tokens = tokens[1:]
+	}
+	for len(tokens) > 0 && tokens[len(tokens)-1].typ == tokenWhitespace {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
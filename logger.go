@@ -0,0 +1,144 @@
+package hocon
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Level is the severity of a message given to the logger.
+type Level int
+
+const (
+	// LevelDebug is used for details of loading.
+	LevelDebug Level = iota
+	// LevelInfo is used for notable events of loading, like default values applied to fields.
+	LevelInfo
+	// LevelWarn is used for problems which do not stop loading, like deprecated or unknown keys.
+	LevelWarn
+	// LevelError is used for failures of loading, like panics.
+	LevelError
+)
+
+func (level Level) String() string {
+	switch level {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return fmt.Sprintf("Level(%d)", int(level))
+}
+
+// Logger receives diagnostic messages of the loader. Fields describe the message in a structured way, like
+// the field and the path it concerns.
+type Logger func(level Level, msg string, fields map[string]interface{})
+
+// WithLogger sets the logger to receive diagnostic messages of the loader: deprecated keys used, default values
+// applied, unknown keys of the configuration and precision loss of float32 fields.
+func WithLogger(logger Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+		opts.used = make(map[value]bool)
+	}
+}
+
+// log gives the message to the logger if it is set.
+func (opts *options) log(level Level, msg string, fields map[string]interface{}) {
+	if opts.logger != nil {
+		opts.logger(level, msg, fields)
+	}
+}
+
+// logPanic reports the panic of the loader before it is propagated.
+func logPanic(reason interface{}, opts []Option) {
+	newOptions(opts).log(LevelError, "cannot parse config: panic", map[string]interface{}{"panic": reason})
+}
+
+// markUsed remembers that the value is decoded to a field, so it is not reported as unknown.
+func (opts *options) markUsed(hoconValue value) {
+	if opts.used != nil && hoconValue != nil {
+		opts.used[hoconValue] = true
+	}
+}
+
+// reportUnknown reports keys of the configuration which are decoded to no field. Keys of objects decoded as a
+// whole, like maps or configs, are known. Profile sections are not reported if profiles are active.
+func (opts *options) reportUnknown(obj *objectValue, prefix string) {
+	if opts.used == nil {
+		return
+	}
+	for _, key := range obj.keys {
+		if prefix == "" && key == profilesKey && len(opts.profiles) > 0 {
+			continue
+		}
+		child := obj.fields[key]
+		if opts.used[child] {
+			continue
+		}
+		path := prefix + key
+		if nested, ok := child.(*objectValue); ok && len(nested.keys) > 0 {
+			opts.reportUnknown(nested, path+".")
+			continue
+		}
+		opts.log(LevelWarn, fmt.Sprintf("unknown key %s", path), map[string]interface{}{
+			"path": path, "origin": child.pos()})
+	}
+}
+
+// logDefault reports the default value applied to the field.
+func (opts *options) logDefault(parentName string, field *reflect.StructField, path string, rawDefault string) {
+	if opts.logger == nil {
+		return
+	}
+	name := fieldName(parentName, field)
+	if isSecretLogged(field) {
+		rawDefault = redactedText
+	}
+	opts.log(LevelInfo, fmt.Sprintf("default value %s is used for %s", rawDefault, name),
+		map[string]interface{}{"field": name, "path": path, "default": rawDefault})
+}
+
+// checkPrecision reports the float32 field which cannot keep the configured value exactly.
+func (opts *options) checkPrecision(parentName string, field *reflect.StructField, path string, hoconValue value) {
+	if opts.logger == nil {
+		return
+	}
+	rawValue, _ := text(hoconValue)
+	expected, err := strconv.ParseFloat(rawValue, 64)
+	if err != nil {
+		return
+	}
+	converted := float32(expected)
+	actual, _ := strconv.ParseFloat(strconv.FormatFloat(float64(converted), 'g', -1, 32), 64)
+	if actual != expected || math.IsInf(float64(converted), 0) != math.IsInf(expected, 0) {
+		name := fieldName(parentName, field)
+		var rawConverted interface{} = converted
+		if isSecretLogged(field) {
+			rawValue, rawConverted = redactedText, redactedText
+		}
+		opts.log(LevelWarn, fmt.Sprintf("value %s of %s loses precision as float32", rawValue, name),
+			map[string]interface{}{"field": name, "path": path, "value": rawValue,
+				"float32": rawConverted, "origin": hoconValue.pos()})
+	}
+}
+
+// isSecretLogged checks if values of the field must be masked in log messages.
+func isSecretLogged(field *reflect.StructField) bool {
+	if isSecretField(field) {
+		return true
+	}
+	typ := field.Type
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+		if typ == secretType {
+			return true
+		}
+		typ = typ.Elem()
+	}
+	return typ == secretType
+}
@@ -0,0 +1,104 @@
+package hocon
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy converts Go field name to HOCON key. It is used for the fields which have neither path nor
+// node tag key.
+type NamingStrategy func(fieldName string) string
+
+// Verbatim uses field name as is, like `MaxConns`. It is the default naming strategy.
+func Verbatim(fieldName string) string {
+	return fieldName
+}
+
+// LowerCamelCase converts field name like `MaxConns` or `HTTPServer` to `maxConns` or `httpServer`.
+func LowerCamelCase(fieldName string) string {
+	words := splitWords(fieldName)
+	if len(words) == 0 {
+		return fieldName
+	}
+	words[0] = strings.ToLower(words[0])
+	return strings.Join(words, "")
+}
+
+// KebabCase converts field name like `MaxConns` or `HTTPServer` to `max-conns` or `http-server`.
+func KebabCase(fieldName string) string {
+	return strings.ToLower(strings.Join(splitWords(fieldName), "-"))
+}
+
+// SnakeCase converts field name like `MaxConns` or `HTTPServer` to `max_conns` or `http_server`.
+func SnakeCase(fieldName string) string {
+	return strings.ToLower(strings.Join(splitWords(fieldName), "_"))
+}
+
+// WithNaming sets the strategy to make keys from field names.
+func WithNaming(strategy NamingStrategy) Option {
+	return func(opts *options) {
+		opts.naming = strategy
+	}
+}
+
+// WithCaseInsensitiveKeys makes the loader match keys of the configuration ignoring case. Exact match is
+// preferred, otherwise the first key which differs in case only is used.
+func WithCaseInsensitiveKeys() Option {
+	return func(opts *options) {
+		opts.caseInsensitive = true
+	}
+}
+
+// splitWords splits field name to words by case changes keeping acronyms together, like `HTTPServer2Port` to
+// `HTTP`, `Server2` and `Port`. Underscores separate words too.
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		switch {
+		case i == len(runes):
+		case runes[i] == '_':
+		case unicode.IsUpper(runes[i]) && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])):
+		case unicode.IsUpper(runes[i]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]):
+		default:
+			continue
+		}
+		if i > start {
+			words = append(words, string(runes[start:i]))
+		}
+		start = i
+		if i < len(runes) && runes[i] == '_' {
+			start = i + 1
+		}
+	}
+	return words
+}
+
+// find returns the value by given path using case-insensitive matching if it is requested.
+func (opts *options) find(config *objectValue, path []string) value {
+	if !opts.caseInsensitive {
+		return config.find(path)
+	}
+	var current value = config
+	for _, key := range path {
+		obj, ok := current.(*objectValue)
+		if !ok {
+			return nil
+		}
+		next, exists := obj.fields[key]
+		if !exists {
+			for _, candidate := range obj.keys {
+				if strings.EqualFold(candidate, key) {
+					next, exists = obj.fields[candidate], true
+					break
+				}
+			}
+		}
+		if !exists {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
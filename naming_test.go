@@ -0,0 +1,86 @@
+package hocon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamingStrategies(t *testing.T) {
+	cases := []struct {
+		name, lowerCamel, kebab, snake string
+	}{
+		{"MaxConns", "maxConns", "max-conns", "max_conns"},
+		{"HTTPServer", "httpServer", "http-server", "http_server"},
+		{"URL", "url", "url", "url"},
+		{"DBHost2Port", "dbHost2Port", "db-host2-port", "db_host2_port"},
+		{"Retry_Count", "retryCount", "retry-count", "retry_count"},
+		{"X", "x", "x", "x"},
+		{"already", "already", "already", "already"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.name, Verbatim(c.name))
+		assert.Equal(t, c.lowerCamel, LowerCamelCase(c.name), c.name)
+		assert.Equal(t, c.kebab, KebabCase(c.name), c.name)
+		assert.Equal(t, c.snake, SnakeCase(c.name), c.name)
+	}
+}
+
+type namingProperties struct {
+	MaxConns   int32
+	HTTPServer struct {
+		ReadTimeout string
+		Port        int32 `hocon:"node=PORT"`
+	}
+	Fixed string `hocon:"path=Some.Path"`
+}
+
+func TestLoadWithNaming(t *testing.T) {
+	var props namingProperties
+	err := LoadConfigText("max-conns: 10, http-server { read-timeout: 5s, PORT: 80 }, Some.Path: x", &props,
+		WithNaming(KebabCase))
+	if assert.Nil(t, err) {
+		assert.Equal(t, int32(10), props.MaxConns)
+		assert.Equal(t, "5s", props.HTTPServer.ReadTimeout)
+		assert.Equal(t, int32(80), props.HTTPServer.Port)
+		assert.Equal(t, "x", props.Fixed)
+		assert.Equal(t, "max_conns = 10\nhttp_server {\n  read_timeout = \"5s\"\n  PORT = 80\n}\nSome {\n"+
+			"  Path = \"x\"\n}\n", Dump(props, WithNaming(SnakeCase)))
+	}
+
+	props = namingProperties{}
+	err = LoadConfigText("max_conns: 10, http_server { read_timeout: 5s, PORT: 80 }, Some.Path: x", &props,
+		WithNaming(SnakeCase))
+	if assert.Nil(t, err) {
+		assert.Equal(t, int32(10), props.MaxConns)
+	}
+
+	props = namingProperties{}
+	err = LoadConfigText("maxConns: 10, httpServer { readTimeout: 5s, PORT: 80 }, Some.Path: x", &props,
+		WithNaming(LowerCamelCase))
+	if assert.Nil(t, err) {
+		assert.Equal(t, "5s", props.HTTPServer.ReadTimeout)
+	}
+}
+
+func TestCaseInsensitiveKeys(t *testing.T) {
+	var props namingProperties
+	err := LoadConfigText("MAXCONNS: 10, maxconns: 20, httpserver { READTIMEOUT: 5s, port: 80 }, some.path: x",
+		&props, WithCaseInsensitiveKeys())
+	if assert.Nil(t, err) {
+		assert.Equal(t, int32(10), props.MaxConns)
+		assert.Equal(t, "5s", props.HTTPServer.ReadTimeout)
+		assert.Equal(t, int32(80), props.HTTPServer.Port)
+		assert.Equal(t, "x", props.Fixed)
+	}
+
+	props = namingProperties{}
+	err = LoadConfigText("MaxConns: 10, maxconns: 20, HTTPServer { ReadTimeout: 5s, PORT: 80 }, Some.Path: x",
+		&props, WithCaseInsensitiveKeys())
+	if assert.Nil(t, err) {
+		assert.Equal(t, int32(10), props.MaxConns, "exact match is preferred")
+	}
+
+	err = LoadConfigText("maxconns: 10, httpserver { readtimeout: 5s, port: 80 }, some.path: x", &props)
+	assert.EqualError(t, err, "no value either default value provided for MaxConns []")
+}
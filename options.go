@@ -0,0 +1,73 @@
+package hocon
+
+import "reflect"
+
+// Option changes the way configuration is loaded.
+type Option func(*options)
+
+type options struct {
+	report          *Report
+	resolvers       map[string]Resolver
+	encryptionKey   []byte
+	profiles        []string
+	jsonFiles       bool
+	fileIncludes    IncludeResolver
+	urlIncludes     IncludeResolver
+	types           map[reflect.Type]map[string]reflect.Type
+	merge           bool
+	naming          NamingStrategy
+	caseInsensitive bool
+	logger          Logger
+	used            map[value]bool
+}
+
+func newOptions(opts []Option) *options {
+	result := &options{
+		resolvers:    map[string]Resolver{fileResolver: FileResolver{}},
+		fileIncludes: OSIncludeResolver{},
+		urlIncludes:  HTTPIncludeResolver{},
+		types:        make(map[reflect.Type]map[string]reflect.Type),
+		naming:       Verbatim,
+	}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// WithReport makes the loader add an entry to the report for every loaded field.
+func WithReport(report *Report) Option {
+	return func(opts *options) {
+		opts.report = report
+	}
+}
+
+// WithMergeMode makes the loader overlay the configuration on the current values of the receiver. Fields which
+// have no value in the configuration keep their current values, default tag keys are not used then. Loaded
+// map[string]interface{} values are merged into the current maps, other values are replaced.
+func WithMergeMode() Option {
+	return func(opts *options) {
+		opts.merge = true
+	}
+}
+
+// record adds loaded field to the report if it is requested. Nil hoconValue means the default value is used.
+func (opts *options) record(field string, path string, hoconValue value) {
+	if opts.report == nil {
+		return
+	}
+	entry := ReportEntry{Field: field, Path: path, Source: SourceDefault}
+	if hoconValue != nil {
+		entry.Origin = hoconValue.pos()
+		entry.Source = sourceOf(entry.Origin)
+	}
+	opts.report.Entries = append(opts.report.Entries, entry)
+}
+
+// recordPreset adds the field which keeps its preset value to the report if it is requested.
+func (opts *options) recordPreset(field string, path string) {
+	if opts.report == nil {
+		return
+	}
+	opts.report.Entries = append(opts.report.Entries, ReportEntry{Field: field, Path: path, Source: SourcePreset})
+}
@@ -0,0 +1,533 @@
+package hocon
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var numberRegexp = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// ParseError describes a syntax or substitution error found in HOCON input.
+type ParseError struct {
+	Origin
+	Message string
+}
+
+func newParseError(origin Origin, format string, args ...interface{}) *ParseError {
+	return &ParseError{Origin: origin, Message: fmt.Sprintf(format, args...)}
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("%s: %s", err.Origin, err.Message)
+}
+
+// frame is an object which is being parsed at the moment. Frames are used to find previous values of
+// self-referential fields.
+type frame struct {
+	prefix []string
+	object *objectValue
+}
+
+type parser struct {
+	tokens   []token
+	offset   int
+	frames   []frame
+	inArray  int
+	includer *includer
+}
+
+// parseConfig parses HOCON text, merges active profiles over it and resolves its substitutions. Filename is used
+// in error messages and to find profile files.
+func parseConfig(filename string, input string, opts *options) (*objectValue, error) {
+	root, err := parse(filename, input, newIncluder(opts), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return finishConfig(root, filename, opts)
+}
+
+// finishConfig merges active profiles over the parsed root object and resolves its substitutions.
+func finishConfig(root *objectValue, filename string, opts *options) (*objectValue, error) {
+	root, err := applyProfiles(root, filename, opts)
+	if err != nil {
+		return nil, err
+	}
+	return resolve(root, opts.resolvers)
+}
+
+// parse parses HOCON text to the tree of unresolved values. IncludedFrom is the origin of include directive if
+// the text is an included configuration. Base is the configuration the text is merged over, like earlier files
+// of a directory, self-referential fields like `a = ${a} [2]` or `a += 2` see its values, it may be nil.
+func parse(filename string, input string, inc *includer, includedFrom *Origin, base *objectValue) (*objectValue,
+	error) {
+	l := newLexer(filename, input)
+	l.pos.IncludedFrom = includedFrom
+	tokens, err := l.lex()
+	if err != nil {
+		return nil, err
+	}
+
+	if filename != "" {
+		inc.stack = append(inc.stack, filename)
+		defer func() {
+			inc.stack = inc.stack[:len(inc.stack)-1]
+		}()
+	}
+	p := &parser{tokens: tokens, includer: inc}
+	if base != nil {
+		p.frames = []frame{{object: base}}
+	}
+	return p.parseRoot()
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.offset]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.offset]
+	if tok.typ != tokenEOF {
+		p.offset++
+	}
+	return tok
+}
+
+// skip skips all the following tokens of given types.
+func (p *parser) skip(types ...tokenType) {
+	for {
+		current := p.peek().typ
+		found := false
+		for _, typ := range types {
+			if current == typ {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return
+		}
+		p.advance()
+	}
+}
+
+func (p *parser) parseRoot() (*objectValue, error) {
+	p.skip(tokenWhitespace, tokenNewline)
+	tok := p.peek()
+
+	var root *objectValue
+	var err error
+	switch tok.typ {
+	case tokenOpenBrace:
+		p.advance()
+		root, err = p.parseObject(tok.Origin, nil, true)
+	case tokenOpenBracket:
+		return nil, newParseError(tok.Origin, "root value must be an object, not an array")
+	default:
+		root, err = p.parseObject(tok.Origin, nil, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.skip(tokenWhitespace, tokenNewline)
+	if tok = p.peek(); tok.typ != tokenEOF {
+		return nil, newParseError(tok.Origin, "unexpected %s after the root object", tok.describe())
+	}
+	return root, nil
+}
+
+// parseObject parses object fields until closing brace or end of input for braceless root object. Prefix is
+// the path of the object from the root, it is used to detect self-referential substitutions.
+func (p *parser) parseObject(start Origin, prefix []string, braced bool) (*objectValue, error) {
+	obj := newObject(start)
+	p.frames = append(p.frames, frame{prefix: prefix, object: obj})
+	defer func() {
+		p.frames = p.frames[:len(p.frames)-1]
+	}()
+
+	for {
+		p.skip(tokenWhitespace, tokenNewline)
+		tok := p.peek()
+		if braced && tok.typ == tokenCloseBrace {
+			p.advance()
+			return obj, nil
+		}
+		if tok.typ == tokenEOF {
+			if braced {
+				return nil, newParseError(start, "unclosed object, expected '}'")
+			}
+			return obj, nil
+		}
+
+		if p.isInclude() {
+			err := p.parseInclude(obj, prefix)
+			if err != nil {
+				return nil, err
+			}
+		} else if err := p.parseField(obj, prefix); err != nil {
+			return nil, err
+		}
+
+		p.skip(tokenWhitespace)
+		switch tok = p.peek(); tok.typ {
+		case tokenComma, tokenNewline:
+			p.advance()
+		case tokenCloseBrace, tokenEOF:
+		default:
+			return nil, newParseError(tok.Origin, "expected ',' or newline after field value, got %s",
+				tok.describe())
+		}
+	}
+}
+
+// parseField parses a single `key: value`, `key = value`, `key { ... }` or `key += value` field and puts it
+// into the object.
+func (p *parser) parseField(obj *objectValue, prefix []string) error {
+	start := p.peek()
+	var keyTokens []token
+	for isKeyToken(p.peek().typ) {
+		keyTokens = append(keyTokens, p.advance())
+	}
+	path, err := pathFromTokens(keyTokens)
+	if err != nil {
+		return err
+	}
+	if path == nil {
+		return newParseError(start.Origin, "expected key, got %s", start.describe())
+	}
+
+	appending := false
+	switch tok := p.peek(); tok.typ {
+	case tokenColon, tokenEquals:
+		p.advance()
+	case tokenPlusEquals:
+		p.advance()
+		appending = true
+	case tokenOpenBrace:
+	default:
+		return newParseError(tok.Origin, "expected ':', '=' or '{' after key %s, got %s",
+			renderPath(path), tok.describe())
+	}
+	p.skip(tokenWhitespace, tokenNewline)
+
+	fieldPath := append(append([]string{}, prefix...), path...)
+	if tok := p.peek(); isValueEnd(tok.typ) {
+		return newParseError(tok.Origin, "expected value for key %s, got %s", renderPath(path), tok.describe())
+	}
+	v, err := p.parseValue(fieldPath)
+	if err != nil {
+		return err
+	}
+
+	if p.inArray == 0 {
+		v = replaceSelfReferences(v, fieldPath, nil, p.previous)
+	}
+	if v == nil {
+		// optional substitution without value leaves the field untouched
+		return nil
+	}
+	if appending {
+		array := &arrayValue{Origin: v.pos(), elements: []value{v}}
+		if prev := p.previous(fieldPath); prev != nil {
+			v = &concatValue{Origin: prev.pos(), parts: []value{prev, array}}
+		} else {
+			// `a += x` means `a = ${?a} [x]`, the self-reference has no value but it is kept to append to
+			// the base value if the field is rebased, like fields of profile sections are
+			self := &substValue{Origin: v.pos(), path: fieldPath, optional: true, appending: true}
+			v = &concatValue{Origin: v.pos(), parts: []value{self, array}}
+		}
+	}
+
+	for i := len(path) - 1; i > 0; i-- {
+		wrapper := newObject(start.Origin)
+		wrapper.set(path[i], v)
+		v = wrapper
+	}
+	obj.merge(path[0], v)
+	return nil
+}
+
+// isInclude tells if the next tokens are an include directive: unquoted `include` followed by a quoted name or
+// a function like `file(`. Keys named `include` are followed by a separator or an object.
+func (p *parser) isInclude() bool {
+	if tok := p.peek(); tok.typ != tokenUnquoted || tok.text != "include" {
+		return false
+	}
+	i := p.offset + 1
+	if p.tokens[i].typ != tokenWhitespace {
+		return false
+	}
+	for p.tokens[i].typ == tokenWhitespace {
+		i++
+	}
+	next := p.tokens[i]
+	return next.typ == tokenQuoted || next.typ == tokenUnquoted && strings.HasSuffix(next.text, "(")
+}
+
+// parseInclude parses an include directive like `include required(file("name"))` and merges the fields of
+// included configuration into the object like they are written in place of the directive.
+func (p *parser) parseInclude(obj *objectValue, prefix []string) error {
+	start := p.advance()
+	var opener, closer strings.Builder
+	var name *token
+	for !isValueEnd(p.peek().typ) {
+		tok := p.advance()
+		switch {
+		case tok.typ == tokenWhitespace:
+		case tok.typ == tokenQuoted && name == nil:
+			name = &tok
+		case tok.typ == tokenUnquoted && name == nil:
+			opener.WriteString(tok.text)
+		case tok.typ == tokenUnquoted:
+			closer.WriteString(tok.text)
+		default:
+			return newParseError(tok.Origin, "unexpected %s in include directive", tok.describe())
+		}
+	}
+	if name == nil {
+		return newParseError(start.Origin, "include directive expects a quoted name")
+	}
+
+	spec, err := parseIncludeSpec(start.Origin, opener.String(), name.text, closer.String())
+	if err != nil {
+		return err
+	}
+	included, err := p.includer.include(spec, start.Origin)
+	if err != nil || included == nil {
+		return err
+	}
+	included = relativize(included, prefix).(*objectValue)
+	for _, key := range included.keys {
+		obj.merge(key, included.fields[key])
+	}
+	return nil
+}
+
+func isKeyToken(typ tokenType) bool {
+	return typ == tokenUnquoted || typ == tokenQuoted || typ == tokenWhitespace
+}
+
+func isValueEnd(typ tokenType) bool {
+	switch typ {
+	case tokenNewline, tokenComma, tokenCloseBrace, tokenCloseBracket, tokenEOF:
+		return true
+	}
+	return false
+}
+
+// parseValue parses a value or a concatenation of values until the end of the line, comma or closing bracket.
+// It returns nil if there is no value.
+func (p *parser) parseValue(fieldPath []string) (value, error) {
+	var parts []value
+	var first token
+	var whitespace *token
+	for {
+		tok := p.peek()
+		if isValueEnd(tok.typ) {
+			break
+		}
+		p.advance()
+		if tok.typ == tokenWhitespace {
+			whitespace = &tok
+			continue
+		}
+
+		var part value
+		var err error
+		switch tok.typ {
+		case tokenOpenBrace:
+			part, err = p.parseObject(tok.Origin, fieldPath, true)
+		case tokenOpenBracket:
+			part, err = p.parseArray(tok.Origin)
+		case tokenQuoted, tokenUnquoted:
+			part = &stringValue{Origin: tok.Origin, text: tok.text}
+		case tokenSubstitution:
+			part = &substValue{Origin: tok.Origin, path: tok.path, optional: tok.optional,
+				resolver: tok.resolver, argument: tok.text}
+		default:
+			return nil, newParseError(tok.Origin, "unexpected %s in value", tok.describe())
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(parts) == 0 {
+			first = tok
+		} else if whitespace != nil {
+			parts = append(parts, &stringValue{Origin: whitespace.Origin, text: whitespace.text, ws: true})
+		}
+		whitespace = nil
+		parts = append(parts, part)
+	}
+
+	switch {
+	case len(parts) == 0:
+		return nil, nil
+	case len(parts) == 1 && first.typ == tokenUnquoted:
+		return unquotedValue(first), nil
+	case len(parts) == 1:
+		return parts[0], nil
+	}
+
+	var sb strings.Builder
+	for _, part := range parts {
+		s, ok := part.(*stringValue)
+		if !ok {
+			return &concatValue{Origin: first.Origin, parts: parts}, nil
+		}
+		sb.WriteString(s.text)
+	}
+	return &stringValue{Origin: first.Origin, text: sb.String()}, nil
+}
+
+// unquotedValue makes boolean, null, number or string value from unquoted text.
+func unquotedValue(tok token) value {
+	switch tok.text {
+	case "true":
+		return &booleanValue{Origin: tok.Origin, value: true}
+	case "false":
+		return &booleanValue{Origin: tok.Origin, value: false}
+	case "null":
+		return &nullValue{Origin: tok.Origin}
+	}
+	if numberRegexp.MatchString(tok.text) {
+		return &numberValue{Origin: tok.Origin, text: tok.text}
+	}
+	return &stringValue{Origin: tok.Origin, text: tok.text}
+}
+
+// parseArray parses array elements until closing bracket. Fields of objects inside arrays have no path,
+// so self-referential substitutions are not looked for there.
+func (p *parser) parseArray(start Origin) (*arrayValue, error) {
+	frames := p.frames
+	p.frames = nil
+	p.inArray++
+	defer func() {
+		p.frames = frames
+		p.inArray--
+	}()
+
+	array := &arrayValue{Origin: start}
+	for {
+		p.skip(tokenWhitespace, tokenNewline)
+		tok := p.peek()
+		switch tok.typ {
+		case tokenCloseBracket:
+			p.advance()
+			return array, nil
+		case tokenEOF:
+			return nil, newParseError(start, "unclosed array, expected ']'")
+		}
+
+		element, err := p.parseValue(nil)
+		if err != nil {
+			return nil, err
+		}
+		if element == nil {
+			return nil, newParseError(tok.Origin, "expected array element, got %s", tok.describe())
+		}
+		array.elements = append(array.elements, element)
+
+		p.skip(tokenWhitespace)
+		switch tok = p.peek(); tok.typ {
+		case tokenComma, tokenNewline:
+			p.advance()
+		case tokenCloseBracket:
+		case tokenEOF:
+			return nil, newParseError(start, "unclosed array, expected ']'")
+		default:
+			return nil, newParseError(tok.Origin, "expected ',' or newline after array element, got %s",
+				tok.describe())
+		}
+	}
+}
+
+// previous returns the value which the path has at the moment, including the objects which are being parsed.
+func (p *parser) previous(path []string) value {
+	var result value
+	for _, f := range p.frames {
+		if !hasPrefix(path, f.prefix) {
+			continue
+		}
+		v := f.object.find(path[len(f.prefix):])
+		if v == nil {
+			continue
+		}
+		if obj, ok := v.(*objectValue); ok {
+			v = obj.copy()
+		}
+		if result == nil {
+			result = v
+		} else {
+			result = mergeValues(result, v)
+		}
+	}
+	return result
+}
+
+// replaceSelfReferences replaces substitutions which refer to the field itself or to the paths inside it
+// with the previous values of these paths. Optional ones without previous value are removed, required ones
+// can only be resolved from environment. It returns nil if nothing left from the value.
+//
+// Section is the path of the profile section which is rebased over the base configuration, the appending
+// self-references of `a += x` fields are relative to it then. It is nil for the parser.
+func replaceSelfReferences(v value, fieldPath []string, section []string, previous func(path []string) value) value {
+	switch typed := v.(type) {
+	case *substValue:
+		path := typed.path
+		if typed.appending {
+			if !hasPrefix(path, section) {
+				return typed
+			}
+			path = path[len(section):]
+		}
+		if typed.resolver != "" || !hasPrefix(path, fieldPath) {
+			return typed
+		}
+		if prev := previous(path); prev != nil {
+			return prev
+		}
+		if typed.optional {
+			return nil
+		}
+		return &substValue{Origin: typed.Origin, path: typed.path, envOnly: true}
+
+	case *concatValue:
+		var parts []value
+		for _, part := range typed.parts {
+			if replaced := replaceSelfReferences(part, fieldPath, section, previous); replaced != nil {
+				parts = append(parts, replaced)
+			}
+		}
+		if len(parts) == 0 {
+			return nil
+		}
+		return &concatValue{Origin: typed.Origin, parts: parts}
+
+	case *mergeValue:
+		var parts []value
+		for _, part := range typed.parts {
+			if replaced := replaceSelfReferences(part, fieldPath, section, previous); replaced != nil {
+				parts = append(parts, replaced)
+			}
+		}
+		switch len(parts) {
+		case 0:
+			return nil
+		case 1:
+			return parts[0]
+		}
+		return &mergeValue{Origin: typed.Origin, parts: parts}
+
+	case *arrayValue:
+		array := &arrayValue{Origin: typed.Origin}
+		for _, element := range typed.elements {
+			if replaced := replaceSelfReferences(element, fieldPath, section, previous); replaced != nil {
+				array.elements = append(array.elements, replaced)
+			}
+		}
+		return array
+	}
+	return v
+}
@@ -0,0 +1,215 @@
+package hocon
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// plain converts resolved tree to maps, slices and strings to make assertions shorter.
+func plain(v value) interface{} {
+	switch typed := v.(type) {
+	case *objectValue:
+		result := make(map[string]interface{})
+		for _, key := range typed.keys {
+			result[key] = plain(typed.fields[key])
+		}
+		return result
+	case *arrayValue:
+		result := make([]interface{}, 0, len(typed.elements))
+		for _, element := range typed.elements {
+			result = append(result, plain(element))
+		}
+		return result
+	case *nullValue:
+		return nil
+	}
+	s, _ := text(v)
+	return s
+}
+
+func assertParsed(t *testing.T, input string, expected map[string]interface{}) {
+	root, err := parseConfig("", input, newOptions(nil))
+	if assert.Nil(t, err, input) {
+		assert.Equal(t, expected, plain(root), input)
+	}
+}
+
+func assertParseError(t *testing.T, input string, regex string) {
+	_, err := parseConfig("", input, newOptions(nil))
+	if assert.Error(t, err, input) {
+		assert.Regexp(t, regex, err, input)
+	}
+}
+
+func TestParseScalars(t *testing.T) {
+	root, err := parseConfig("", "a: 1, b: 1.5e3, c: true, d: null, e: text, f: \"quoted\"", newOptions(nil))
+	if assert.Nil(t, err) {
+		assert.IsType(t, &numberValue{}, root.fields["a"])
+		assert.IsType(t, &numberValue{}, root.fields["b"])
+		assert.IsType(t, &booleanValue{}, root.fields["c"])
+		assert.IsType(t, &nullValue{}, root.fields["d"])
+		assert.IsType(t, &stringValue{}, root.fields["e"])
+		assert.IsType(t, &stringValue{}, root.fields["f"])
+		assert.Equal(t, "1.5e3", plain(root.fields["b"]))
+	}
+}
+
+func TestParseBraces(t *testing.T) {
+	expected := map[string]interface{}{"a": "1", "b": map[string]interface{}{"c": "2"}}
+	assertParsed(t, "{a: 1, b {c: 2}}", expected)
+	assertParsed(t, "a = 1\nb {\n  c = 2\n}\n", expected)
+	assertParsed(t, "\n// comment\n{ a: 1, # comment\n b: { c: 2, }, }\n", expected)
+}
+
+func TestParseStrings(t *testing.T) {
+	assertParsed(t, `a: "x\ty\u0041\"", b: foo bar  baz, c: "x" y "z"`, map[string]interface{}{
+		"a": "x\tyA\"",
+		"b": "foo bar  baz",
+		"c": "x y z",
+	})
+	assertParsed(t, "a: \"\"\"line1\n\"line2\"\"\"\"", map[string]interface{}{"a": "line1\n\"line2\""})
+}
+
+func TestParsePaths(t *testing.T) {
+	assertParsed(t, `a.b.c: 1, "x.y".z: 2, a.b.d: 3, "": 4`, map[string]interface{}{
+		"a":   map[string]interface{}{"b": map[string]interface{}{"c": "1", "d": "3"}},
+		"x.y": map[string]interface{}{"z": "2"},
+		"":    "4",
+	})
+}
+
+func TestParseDuplicateKeys(t *testing.T) {
+	assertParsed(t, "a {x: 1, y: 2}, a {y: 3, z: 4}, b {x: 1}, b: 5, c: 6, c {x: 1}", map[string]interface{}{
+		"a": map[string]interface{}{"x": "1", "y": "3", "z": "4"},
+		"b": "5",
+		"c": map[string]interface{}{"x": "1"},
+	})
+}
+
+func TestParseArrays(t *testing.T) {
+	assertParsed(t, "a: [1, 2,\n 3\n], b: [[x], {y: z}], c: [1] [2, 3], d: []", map[string]interface{}{
+		"a": []interface{}{"1", "2", "3"},
+		"b": []interface{}{[]interface{}{"x"}, map[string]interface{}{"y": "z"}},
+		"c": []interface{}{"1", "2", "3"},
+		"d": []interface{}{},
+	})
+}
+
+func TestParseSubstitutions(t *testing.T) {
+	assertParsed(t, "a: 1, b: ${a}, c: ${a}${a}, d: x ${a} y, e { f: ${a} }, g: ${e.f}, h: ${?nothing}",
+		map[string]interface{}{
+			"a": "1",
+			"b": "1",
+			"c": "11",
+			"d": "x 1 y",
+			"e": map[string]interface{}{"f": "1"},
+			"g": "1",
+		})
+	assertParsed(t, "a: ${b.c}, b { c: ${d}, e: ${b.c} }, d: 2", map[string]interface{}{
+		"a": "2",
+		"b": map[string]interface{}{"c": "2", "e": "2"},
+		"d": "2",
+	})
+	assertParsed(t, "a {x: 1}, b: ${a} {y: 2}, c: [1] ${l}, l: [2]", map[string]interface{}{
+		"a": map[string]interface{}{"x": "1"},
+		"b": map[string]interface{}{"x": "1", "y": "2"},
+		"c": []interface{}{"1", "2"},
+		"l": []interface{}{"2"},
+	})
+}
+
+func TestParseSelfReferences(t *testing.T) {
+	assertParsed(t, "a: 1, a: ${a}2, b: [x], b: ${b} [y], c += 1, c += 2, d: ${?d} z", map[string]interface{}{
+		"a": "12",
+		"b": []interface{}{"x", "y"},
+		"c": []interface{}{"1", "2"},
+		"d": " z",
+	})
+	assertParsed(t, "a {x: 1}, a: ${a} {y: 2}, b {c: 1}, b { c: ${b.c} 2 }", map[string]interface{}{
+		"a": map[string]interface{}{"x": "1", "y": "2"},
+		"b": map[string]interface{}{"c": "1 2"},
+	})
+	assertParsed(t, "a {x: 1}, a: ${b}, b {y: 2}", map[string]interface{}{
+		"a": map[string]interface{}{"x": "1", "y": "2"},
+		"b": map[string]interface{}{"y": "2"},
+	})
+}
+
+func TestParseEnvironment(t *testing.T) {
+	assert.Nil(t, os.Setenv("HOCON_TEST_VAR", "env"))
+	defer func() {
+		_ = os.Unsetenv("HOCON_TEST_VAR")
+	}()
+	assertParsed(t, "a: ${HOCON_TEST_VAR}, HOCON_TEST_VAR: ${HOCON_TEST_VAR}", map[string]interface{}{
+		"a":              "env",
+		"HOCON_TEST_VAR": "env",
+	})
+}
+
+func TestParseErrors(t *testing.T) {
+	assertParseError(t, "a: 1+1", "^1:5: '\\+' is not allowed")
+	assertParseError(t, "a: 1\nb: $x", "^2:4: '\\$' is not allowed")
+	assertParseError(t, "a: \"abc", "^1:4: unclosed quoted string")
+	assertParseError(t, "a: \"\"\"abc", "^1:4: unclosed triple-quoted string")
+	assertParseError(t, "{a: 1", "^1:1: unclosed object")
+	assertParseError(t, "a: [1, 2", "^1:4: unclosed array")
+	assertParseError(t, "a: [1,, 2]", "^1:7: expected array element")
+	assertParseError(t, "a: 1,, b: 2", "^1:6: expected key")
+	assertParseError(t, "a\nb: 1", "^1:2: expected ':', '=' or '{' after key a")
+	assertParseError(t, "a:", "^1:3: expected value for key a")
+	assertParseError(t, "a..b: 1", "^1:1: path has an empty element")
+	assertParseError(t, "[1, 2]", "^1:1: root value must be an object")
+	assertParseError(t, "{a: 1} b", "^1:8: unexpected 'b' after the root object")
+	assertParseError(t, "a: ${b", "^1:4: unclosed substitution")
+	assertParseError(t, "a: ${b}", "^1:4: could not resolve substitution \\${b}")
+	assertParseError(t, "a: ${b}, b: ${a}", "cycle in substitutions")
+	assertParseError(t, "a: [1] x", "^1:8: cannot concatenate array with string")
+	assertParseError(t, "a: \"\\q\"", "^1:5: unknown escape sequence")
+}
+
+func TestParseErrorType(t *testing.T) {
+	_, err := parseConfig("app.conf", "a {\n  b: ]\n}", newOptions(nil))
+	var parseErr *ParseError
+	if assert.True(t, errors.As(err, &parseErr)) {
+		assert.Equal(t, &ParseError{Origin: Origin{Filename: "app.conf", Line: 2, Column: 6},
+			Message: "expected value for key b, got ']'"}, parseErr)
+		assert.Equal(t, "app.conf:2:6: expected value for key b, got ']'", parseErr.Error())
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	path, err := parsePath(`a.b."c.d".e`)
+	if assert.Nil(t, err) {
+		assert.Equal(t, []string{"a", "b", "c.d", "e"}, path)
+	}
+	_, err = parsePath("a:b")
+	assert.Error(t, err)
+	_, err = parsePath("")
+	assert.Error(t, err)
+	assert.Equal(t, `a."b.c"."".d`, renderPath([]string{"a", "b.c", "", "d"}))
+}
+
+func TestParseFileResolver(t *testing.T) {
+	file, err1 := makeTestFile("value.txt")
+	if assert.Nil(t, err1, "cannot create temp file") {
+		defer func() {
+			_ = deleteTestFile(file)
+		}()
+		_, err2 := file.WriteString("\tfrom file \n")
+		if assert.Nil(t, err2) {
+			assertParsed(t, "a: ${file:"+file.Name()+"}, b: x${?file:/nonexistent}y, c: ${file: "+file.Name()+" }\"!\"",
+				map[string]interface{}{
+					"a": "from file",
+					"b": "xy",
+					"c": "from file!",
+				})
+		}
+	}
+	assertParseError(t, "a: ${file:/nonexistent}", "^1:4: cannot resolve substitution \\${file:/nonexistent}: "+
+		"cannot read value file: .*no such file or directory$")
+	assertParseError(t, "a: ${unknown:x}", "^1:4: unknown resolver unknown")
+	assertParseError(t, "a: ${file:/x", "^1:4: unclosed substitution")
+}
@@ -0,0 +1,92 @@
+package hocon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// profilesKey is the root key of the object which keeps profile sections like `profiles.dev { ... }`.
+const profilesKey = "profiles"
+
+// WithProfiles activates configuration profiles. Every profile is merged over the base configuration before
+// substitutions are resolved, later profiles take precedence over earlier ones. For each profile the section
+// `profiles.<name>` of the configuration is merged first and then the sibling file `<base>-<name>.<ext>` if the
+// configuration is loaded from a file, like `application-dev.conf` for `application.conf`. Missing sections
+// and files are ignored.
+func WithProfiles(names ...string) Option {
+	return func(opts *options) {
+		opts.profiles = append(opts.profiles, names...)
+	}
+}
+
+// applyProfiles merges active profiles over the unresolved root object. Filename is empty for configurations
+// parsed from text, profile files are not looked for then.
+func applyProfiles(root *objectValue, filename string, opts *options) (*objectValue, error) {
+	for _, name := range opts.profiles {
+		if name == "" {
+			return nil, fmt.Errorf("profile name cannot be empty")
+		}
+
+		if section := root.find([]string{profilesKey, name}); section != nil {
+			if _, ok := section.(*objectValue); !ok {
+				return nil, newParseError(section.pos(), "profile %s must be an object, not %s", name,
+					typeName(section))
+			}
+			sectionPath := []string{profilesKey, name}
+			root = mergeValues(root, rebaseSection(section.(*objectValue), sectionPath, nil, root)).(*objectValue)
+		}
+
+		if filename == "" {
+			continue
+		}
+		profileFile := profileFilename(filename, name)
+		content, err := ioutil.ReadFile(profileFile)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read profile file: %w", err)
+		}
+		profileRoot, err := parse(profileFile, string(content), newIncluder(opts), nil, root)
+		if err != nil {
+			return nil, err
+		}
+		root = mergeValues(root, profileRoot).(*objectValue)
+	}
+	return root, nil
+}
+
+// rebaseSection replaces self-references of the active profile section fields, like `a = ${a} [2]` or `a += 2`,
+// with the values the fields have in the base configuration. SectionPath is the path of the section like
+// `profiles.dev`, prefix is the path of the object inside the section.
+func rebaseSection(section *objectValue, sectionPath []string, prefix []string, base *objectValue) *objectValue {
+	previous := func(path []string) value {
+		v := base.find(path)
+		if obj, ok := v.(*objectValue); ok {
+			return obj.copy()
+		}
+		return v
+	}
+	rebased := newObject(section.Origin)
+	for _, key := range section.keys {
+		fieldPath := append(append([]string{}, prefix...), key)
+		v := section.fields[key]
+		if obj, ok := v.(*objectValue); ok {
+			v = rebaseSection(obj, sectionPath, fieldPath, base)
+		} else if v = replaceSelfReferences(v, fieldPath, sectionPath, previous); v == nil {
+			continue
+		}
+		rebased.set(key, v)
+	}
+	return rebased
+}
+
+// profileFilename returns the name of the profile file located next to the base file, like `conf/app-dev.conf`
+// for `conf/app.conf`.
+func profileFilename(filename string, profile string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "-" + profile + ext
+}
@@ -0,0 +1,86 @@
+package hocon
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Source is a kind of the place where the value of a field came from.
+type Source int
+
+const (
+	// SourceDefault means the value is taken from the default tag key.
+	SourceDefault Source = iota
+	// SourceFile means the value is taken from a configuration file.
+	SourceFile
+	// SourceText means the value is taken from the text given to LoadConfigText or ParseConfigText.
+	SourceText
+	// SourceEnv means the value is substituted from an environment variable.
+	SourceEnv
+	// SourceResolver means the value is provided by a resolver, like ${file:/path}.
+	SourceResolver
+	// SourcePreset means the field keeps the value it had before loading as there is no value for it, like
+	// optional fields or fields loaded in merge mode.
+	SourcePreset
+)
+
+func (source Source) String() string {
+	switch source {
+	case SourceDefault:
+		return "default"
+	case SourceFile:
+		return "file"
+	case SourceText:
+		return "text"
+	case SourceEnv:
+		return "env"
+	case SourceResolver:
+		return "resolver"
+	case SourcePreset:
+		return "preset"
+	}
+	return fmt.Sprintf("Source(%d)", int(source))
+}
+
+func sourceOf(origin Origin) Source {
+	switch {
+	case origin.Env != "":
+		return SourceEnv
+	case origin.Resolver != "":
+		return SourceResolver
+	case origin.Filename != "":
+		return SourceFile
+	}
+	return SourceText
+}
+
+// ReportEntry describes where the value of a single field came from. Origin is empty for default and preset
+// values.
+type ReportEntry struct {
+	Field  string
+	Path   string
+	Source Source
+	Origin Origin
+}
+
+// Report is filled by the loader when WithReport option is given, it has an entry for every loaded field.
+type Report struct {
+	Entries []ReportEntry
+}
+
+// String renders the report as a table with field, path, source and origin columns.
+func (report *Report) String() string {
+	var sb strings.Builder
+	writer := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "FIELD\tPATH\tSOURCE\tORIGIN")
+	for _, entry := range report.Entries {
+		origin := "-"
+		if entry.Source != SourceDefault && entry.Source != SourcePreset {
+			origin = entry.Origin.String()
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", entry.Field, entry.Path, entry.Source, origin)
+	}
+	_ = writer.Flush()
+	return sb.String()
+}
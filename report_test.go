@@ -0,0 +1,89 @@
+package hocon
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	assert.Nil(t, os.Setenv("HOCON_REPORT_TEST", "env"))
+	defer func() {
+		_ = os.Unsetenv("HOCON_REPORT_TEST")
+	}()
+
+	props1 := struct {
+		Inner struct {
+			Key9 int32
+			Key0 string `hocon:"default=zero"`
+		} `hocon:"node=container1"`
+		Env  string `hocon:"path=env"`
+		List []int8 `hocon:"path=list"`
+	}{}
+	var report Report
+	err1 := LoadConfigText("container1: {Key9: 9}\nenv: ${HOCON_REPORT_TEST}\nlist: [1, 2]", &props1,
+		WithReport(&report))
+	if assert.Nil(t, err1) {
+		assert.Equal(t, []ReportEntry{
+			{Field: "Inner.Key9", Path: "container1.Key9", Source: SourceText, Origin: Origin{Line: 1, Column: 20}},
+			{Field: "Inner.Key0", Path: "container1.Key0", Source: SourceDefault},
+			{Field: "Env", Path: "env", Source: SourceEnv,
+				Origin: Origin{Line: 2, Column: 6, Env: "HOCON_REPORT_TEST"}},
+			{Field: "List", Path: "list", Source: SourceText, Origin: Origin{Line: 3, Column: 7}},
+		}, report.Entries)
+
+		assert.Equal(t, ""+
+			"FIELD       PATH             SOURCE   ORIGIN\n"+
+			"Inner.Key9  container1.Key9  text     1:20\n"+
+			"Inner.Key0  container1.Key0  default  -\n"+
+			"Env         env              env      env HOCON_REPORT_TEST at 2:6\n"+
+			"List        list             text     3:7\n", report.String())
+	}
+}
+
+func TestReportPreset(t *testing.T) {
+	props1 := struct {
+		Key1  string `hocon:"optional"`
+		Inner struct {
+			Key2 string
+		} `hocon:"optional"`
+		Key3 string `hocon:"default=three"`
+	}{Key1: "one"}
+	var report Report
+	err1 := LoadConfigText("", &props1, WithReport(&report))
+	if assert.Nil(t, err1) {
+		assert.Equal(t, []ReportEntry{
+			{Field: "Key1", Path: "Key1", Source: SourcePreset},
+			{Field: "Inner", Path: "Inner", Source: SourcePreset},
+			{Field: "Key3", Path: "Key3", Source: SourceDefault},
+		}, report.Entries)
+		assert.Equal(t, "preset", SourcePreset.String())
+	}
+
+	props2 := struct {
+		Key1 string
+		Key2 string `hocon:"default=two"`
+	}{Key1: "one", Key2: "preset"}
+	report = Report{}
+	err2 := LoadConfigText("", &props2, WithReport(&report), WithMergeMode())
+	if assert.Nil(t, err2) {
+		assert.Equal(t, ""+
+			"FIELD  PATH  SOURCE  ORIGIN\n"+
+			"Key1   Key1  preset  -\n"+
+			"Key2   Key2  preset  -\n", report.String())
+	}
+}
+
+func TestReportFile(t *testing.T) {
+	props1 := struct {
+		Key3 int64
+	}{}
+	var report Report
+	err1 := LoadConfigFile("tests/conf1.conf", &props1, WithReport(&report))
+	if assert.Nil(t, err1) && assert.Len(t, report.Entries, 1) {
+		assert.Equal(t, SourceFile, report.Entries[0].Source)
+		assert.Equal(t, "tests/conf1.conf", report.Entries[0].Origin.Filename)
+		assert.Equal(t, "file", report.Entries[0].Source.String())
+	}
+}
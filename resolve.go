@@ -0,0 +1,290 @@
+package hocon
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// substitutor replaces substitutions, concatenations and delayed merges with final values. Values resolved by
+// path are cached, paths which are being resolved at the moment are used to detect cycles. Substitutions with
+// prefix are given to the registered resolvers.
+type substitutor struct {
+	root      *objectValue
+	resolvers map[string]Resolver
+	resolved  map[string]value
+	pending   map[string]bool
+}
+
+// resolve returns a copy of the tree with all the values resolved.
+func resolve(root *objectValue, resolvers map[string]Resolver) (*objectValue, error) {
+	r := &substitutor{
+		root:      root,
+		resolvers: resolvers,
+		resolved:  make(map[string]value),
+		pending:   make(map[string]bool),
+	}
+	v, err := r.resolveValue(root, []string{})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*objectValue), nil
+}
+
+func cacheKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+// resolvePath resolves the value which is located by given path in the tree. It returns nil if an optional
+// substitution inside the value has no value.
+func (r *substitutor) resolvePath(path []string, v value) (value, error) {
+	key := cacheKey(path)
+	if result, ok := r.resolved[key]; ok {
+		return result, nil
+	}
+	if r.pending[key] {
+		return nil, newParseError(v.pos(), "cycle in substitutions, %s refers to itself", renderPath(path))
+	}
+
+	r.pending[key] = true
+	result, err := r.resolveValue(v, path)
+	delete(r.pending, key)
+	if err != nil {
+		return nil, err
+	}
+	r.resolved[key] = result
+	return result, nil
+}
+
+// resolveValue resolves given value. Path is used to cache fields of objects, it is nil for the values
+// which are not located in the tree by themselves, like parts of concatenations or array elements.
+func (r *substitutor) resolveValue(v value, path []string) (value, error) {
+	switch typed := v.(type) {
+	case *objectValue:
+		obj := newObject(typed.Origin)
+		for _, key := range typed.keys {
+			var field value
+			var err error
+			if path != nil {
+				field, err = r.resolvePath(append(append([]string{}, path...), key), typed.fields[key])
+			} else {
+				field, err = r.resolveValue(typed.fields[key], nil)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if field != nil {
+				obj.set(key, field)
+			}
+		}
+		return obj, nil
+
+	case *arrayValue:
+		array := &arrayValue{Origin: typed.Origin}
+		for _, element := range typed.elements {
+			resolved, err := r.resolveValue(element, nil)
+			if err != nil {
+				return nil, err
+			}
+			if resolved != nil {
+				array.elements = append(array.elements, resolved)
+			}
+		}
+		return array, nil
+
+	case *substValue:
+		return r.resolveSubstitution(typed)
+
+	case *concatValue:
+		var parts []value
+		for _, part := range typed.parts {
+			resolved, err := r.resolveValue(part, nil)
+			if err != nil {
+				return nil, err
+			}
+			if resolved != nil {
+				parts = append(parts, resolved)
+			}
+		}
+		return concatenate(typed.Origin, parts)
+
+	case *mergeValue:
+		var result value
+		for _, part := range typed.parts {
+			resolved, err := r.resolveValue(part, nil)
+			if err != nil {
+				return nil, err
+			}
+			if resolved == nil {
+				continue
+			}
+			list, isList := result.(*arrayValue)
+			obj, isObject := resolved.(*objectValue)
+			switch {
+			case result == nil:
+				result = resolved
+			case isList && isObject && isIndexed(obj):
+				if result, err = mergeIndexed(list, obj); err != nil {
+					return nil, err
+				}
+			default:
+				result = mergeValues(result, resolved)
+			}
+		}
+		// merged objects may keep delayed merges of their fields
+		return r.resolveValue(result, nil)
+	}
+	return v, nil
+}
+
+// resolveSubstitution looks for the substitution path in the tree and then in environment variables.
+// Substitutions of included configurations are looked for by the full path first and then by the path
+// written in the included configuration.
+func (r *substitutor) resolveSubstitution(subst *substValue) (value, error) {
+	if subst.resolver != "" {
+		return r.resolveWithResolver(subst)
+	}
+	if subst.appending {
+		// `a += x` without previous value of the field
+		return nil, nil
+	}
+
+	if !subst.envOnly {
+		found, err := r.lookup(subst.path)
+		if err == nil && found == nil && subst.prefixLen > 0 {
+			found, err = r.lookup(subst.path[subst.prefixLen:])
+		}
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			return found, nil
+		}
+	}
+
+	name := strings.Join(subst.path[subst.prefixLen:], ".")
+	if env, ok := os.LookupEnv(name); ok {
+		origin := subst.Origin
+		origin.Env = name
+		return &stringValue{Origin: origin, text: env}, nil
+	}
+	if subst.optional {
+		return nil, nil
+	}
+	return nil, newParseError(subst.Origin, "could not resolve substitution ${%s} to a value",
+		renderPath(subst.path[subst.prefixLen:]))
+}
+
+// resolveWithResolver resolves substitutions like ${file:/path} by the resolver registered for the prefix.
+func (r *substitutor) resolveWithResolver(subst *substValue) (value, error) {
+	resolver, ok := r.resolvers[subst.resolver]
+	if !ok {
+		return nil, newParseError(subst.Origin, "unknown resolver %s in substitution", subst.resolver)
+	}
+	content, found, err := resolver.Resolve(subst.argument)
+	if err != nil && subst.optional && errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newParseError(subst.Origin, "cannot resolve substitution ${%s:%s}: %s", subst.resolver,
+			subst.argument, err)
+	}
+	if !found {
+		if subst.optional {
+			return nil, nil
+		}
+		return nil, newParseError(subst.Origin, "could not resolve substitution ${%s:%s} to a value",
+			subst.resolver, subst.argument)
+	}
+	origin := subst.Origin
+	origin.Resolver = subst.resolver + ":" + subst.argument
+	return &stringValue{Origin: origin, text: content}, nil
+}
+
+// lookup finds and resolves the value by path. Objects on the way are not resolved entirely to avoid false
+// cycles when a field refers to its sibling.
+func (r *substitutor) lookup(path []string) (value, error) {
+	var current value = r.root
+	for i, key := range path {
+		obj, ok := current.(*objectValue)
+		if !ok {
+			return nil, nil
+		}
+		field, ok := obj.fields[key]
+		if !ok {
+			return nil, nil
+		}
+		if _, ok := field.(*objectValue); ok && i < len(path)-1 {
+			current = field
+			continue
+		}
+		resolved, err := r.resolvePath(path[:i+1], field)
+		if err != nil || resolved == nil {
+			return nil, err
+		}
+		current = resolved
+	}
+	return current, nil
+}
+
+// concatenate joins resolved parts of concatenation. Strings and other scalars are joined as text,
+// arrays are joined together, objects are merged. Whitespaces between arrays and objects are ignored.
+func concatenate(pos Origin, parts []value) (value, error) {
+	if len(parts) == 0 {
+		return nil, nil
+	}
+
+	hasContainers := false
+	for _, part := range parts {
+		switch part.(type) {
+		case *objectValue, *arrayValue:
+			hasContainers = true
+		}
+	}
+	if hasContainers {
+		var containers []value
+		for _, part := range parts {
+			if s, ok := part.(*stringValue); !ok || !s.ws {
+				containers = append(containers, part)
+			}
+		}
+		parts = containers
+	}
+
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+
+	switch first := parts[0].(type) {
+	case *objectValue:
+		var result value = first
+		for _, part := range parts[1:] {
+			if _, ok := part.(*objectValue); !ok {
+				return nil, newParseError(part.pos(), "cannot concatenate object with %s", typeName(part))
+			}
+			result = mergeValues(result, part)
+		}
+		return result, nil
+
+	case *arrayValue:
+		array := &arrayValue{Origin: first.Origin}
+		for _, part := range parts {
+			typed, ok := part.(*arrayValue)
+			if !ok {
+				return nil, newParseError(part.pos(), "cannot concatenate array with %s", typeName(part))
+			}
+			array.elements = append(array.elements, typed.elements...)
+		}
+		return array, nil
+	}
+
+	var sb strings.Builder
+	for _, part := range parts {
+		s, ok := text(part)
+		if !ok {
+			return nil, newParseError(part.pos(), "cannot concatenate string with %s", typeName(part))
+		}
+		sb.WriteString(s)
+	}
+	return &stringValue{Origin: pos, text: sb.String()}, nil
+}
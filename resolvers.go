@@ -0,0 +1,51 @@
+package hocon
+
+// fileResolver is the name of the resolver which reads substitution value from the file, like ${file:/path}.
+const fileResolver = "file"
+
+// Resolver provides values for substitutions like ${name:argument}, where name is the prefix the resolver is
+// registered with by WithResolver option. Resolve returns false if there is no value for the argument, then
+// optional substitution is dropped and required one fails. Error fails both of them, except errors which wrap
+// os.ErrNotExist, optional substitutions are dropped for them as well.
+type Resolver interface {
+	Resolve(argument string) (string, bool, error)
+}
+
+// ResolverFunc is an adapter to use ordinary functions as resolvers.
+type ResolverFunc func(argument string) (string, bool, error)
+
+// Resolve calls the function itself.
+func (f ResolverFunc) Resolve(argument string) (string, bool, error) {
+	return f(argument)
+}
+
+// MapResolver is an in-memory resolver which takes values from the map, it is handy for tests.
+type MapResolver map[string]string
+
+// Resolve returns the value stored by the argument.
+func (m MapResolver) Resolve(argument string) (string, bool, error) {
+	result, ok := m[argument]
+	return result, ok, nil
+}
+
+// FileResolver reads the value from the file named by the argument and trims surrounding whitespaces. It is
+// registered as `file` by default. Missing file fails required substitutions with the read error and drops
+// optional ones.
+type FileResolver struct{}
+
+// Resolve reads the file.
+func (FileResolver) Resolve(argument string) (string, bool, error) {
+	content, err := readValueFile(argument)
+	if err != nil {
+		return "", false, err
+	}
+	return content, true, nil
+}
+
+// WithResolver registers the resolver for substitutions with given prefix, like ${secret:db/password} for
+// `secret` prefix. Resolvers with the same prefix replace each other, including the default `file` one.
+func WithResolver(prefix string, resolver Resolver) Option {
+	return func(opts *options) {
+		opts.resolvers[prefix] = resolver
+	}
+}
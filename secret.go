@@ -0,0 +1,29 @@
+package hocon
+
+import "fmt"
+
+// redactedText replaces secret values in any printed output.
+const redactedText = "******"
+
+// Secret is a string which never reveals its value when printed or formatted. Use Value to get the real one.
+type Secret string
+
+// Value returns the secret value itself.
+func (secret Secret) Value() string {
+	return string(secret)
+}
+
+// String returns masked value.
+func (secret Secret) String() string {
+	return redactedText
+}
+
+// GoString returns masked value for %#v verb.
+func (secret Secret) GoString() string {
+	return redactedText
+}
+
+// Format writes masked value for any verb and flags.
+func (secret Secret) Format(state fmt.State, verb rune) {
+	_, _ = fmt.Fprint(state, redactedText)
+}
@@ -0,0 +1,154 @@
+package hocon
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// tagKeys are the keys known by the loader.
+	tagKeys = map[string]interface{}{pathKey: nil, nodeKey: nil, defaultKey: nil, secretKey: nil,
+		fromFileKey: nil, optionalKey: nil, aliasKey: nil, deprecatedKey: nil, sepKey: nil,
+		encodingKey: nil}
+	// flagKeys are the keys which may be written without value meaning `true`, like `hocon:"secret"`.
+	flagKeys = map[string]interface{}{secretKey: nil, fromFileKey: nil, optionalKey: nil, deprecatedKey: nil}
+	// repeatedKeys are the keys which may be given more than once.
+	repeatedKeys = map[string]interface{}{aliasKey: nil}
+)
+
+// tagItem is a single `key=value` item of the tag.
+type tagItem struct {
+	key   string
+	value string
+}
+
+// parseTag parses the tag which is a comma separated list of `key=value` items:
+//   - keys without value are flags meaning `true`, like `optional`, unknown keys are left for mapTag to report;
+//   - values may be quoted with single quotes, a backslash escapes any character inside quotes, like
+//     `default='a,b'` or `default='it\'s'`;
+//   - unquoted values last up to the comma which is not inside brackets, braces, parentheses or double quoted
+//     strings, so HOCON paths and literals like `path="a.b".c` or `default=[1, "2,3"]` are kept whole, and may
+//     contain `=`, like `default=x=y`.
+//
+// Whitespaces around keys and values are ignored.
+func parseTag(stringTag string) ([]tagItem, error) {
+	var items []tagItem
+	for pos := 0; pos < len(stringTag); {
+		end := pos
+		for end < len(stringTag) && stringTag[end] != '=' && stringTag[end] != ',' {
+			end++
+		}
+		item := tagItem{key: strings.TrimSpace(stringTag[pos:end])}
+		if item.key == "" {
+			return nil, fmt.Errorf("tag format error: %s: empty key at %d", stringTag, pos)
+		}
+		if end == len(stringTag) || stringTag[end] == ',' {
+			_, known := tagKeys[item.key]
+			if _, isFlag := flagKeys[item.key]; known && !isFlag {
+				return nil, fmt.Errorf("tag format error: %s: key %s needs a value", stringTag, item.key)
+			}
+			item.value = "true"
+			items = append(items, item)
+			pos = end + 1
+			continue
+		}
+
+		var err error
+		item.value, pos, err = parseTagValue(stringTag, end+1)
+		if err != nil {
+			return nil, fmt.Errorf("tag format error: %s: %w", stringTag, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// parseTagValue parses the value of the tag starting at given position. It returns the value and the position
+// after the comma which ends the value.
+func parseTagValue(stringTag string, pos int) (string, int, error) {
+	for pos < len(stringTag) && stringTag[pos] == ' ' {
+		pos++
+	}
+	start := pos
+	if pos < len(stringTag) && stringTag[pos] == '\'' {
+		var sb strings.Builder
+		for pos++; pos < len(stringTag) && stringTag[pos] != '\''; pos++ {
+			if stringTag[pos] == '\\' && pos+1 < len(stringTag) {
+				pos++
+			}
+			sb.WriteByte(stringTag[pos])
+		}
+		if pos == len(stringTag) {
+			return "", pos, fmt.Errorf("unclosed quote in value %s", stringTag[start:])
+		}
+		for pos++; pos < len(stringTag) && stringTag[pos] == ' '; pos++ {
+		}
+		if pos < len(stringTag) && stringTag[pos] != ',' {
+			return "", pos, fmt.Errorf("unexpected %c after quoted value at %d", stringTag[pos], pos)
+		}
+		return sb.String(), pos + 1, nil
+	}
+
+	depth := 0
+	quoted := false
+	for ; pos < len(stringTag); pos++ {
+		switch c := stringTag[pos]; {
+		case quoted && c == '\\':
+			pos++
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '[' || c == '{' || c == '(':
+			depth++
+		case c == ']' || c == '}' || c == ')':
+			depth--
+		case c == ',' && depth <= 0:
+			return strings.TrimSpace(stringTag[start:pos]), pos + 1, nil
+		}
+	}
+	if quoted {
+		return "", pos, fmt.Errorf("unclosed quote in value %s", stringTag[start:])
+	}
+	return strings.TrimSpace(stringTag[start:]), pos, nil
+}
+
+// mapTag parses StructTag to the map of its keys and values. Unknown and duplicate keys are errors.
+func mapTag(structTag reflect.StructTag) (map[string]string, error) {
+	stringTag := structTag.Get("hocon")
+	items, err := parseTag(stringTag)
+	if err != nil {
+		return nil, err
+	}
+	tagMap := make(map[string]string)
+	for _, item := range items {
+		if _, known := tagKeys[item.key]; !known {
+			return nil, fmt.Errorf("tag format error: %s: unknown key %s", stringTag, item.key)
+		}
+		if _, repeated := repeatedKeys[item.key]; !repeated {
+			if _, exists := tagMap[item.key]; exists {
+				return nil, fmt.Errorf("tag format error: %s: duplicate key %s", stringTag, item.key)
+			}
+		}
+		if item.key == pathKey || item.key == nodeKey || item.key == aliasKey {
+			if _, err := parsePath(item.value); err != nil {
+				return nil, fmt.Errorf("tag format error: %s: wrong %s: %w", stringTag, item.key, err)
+			}
+		}
+		tagMap[item.key] = item.value
+	}
+	return tagMap, nil
+}
+
+// tagAliases returns the values of all the alias tag keys in their order.
+func tagAliases(structTag reflect.StructTag) []string {
+	// the tag is already checked by mapTag
+	items, _ := parseTag(structTag.Get("hocon"))
+	var aliases []string
+	for _, item := range items {
+		if item.key == aliasKey {
+			aliases = append(aliases, item.value)
+		}
+	}
+	return aliases
+}
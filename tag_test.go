@@ -0,0 +1,78 @@
+package hocon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTag(t *testing.T) {
+	items, err := parseTag(`path="a.b".c, optional,default='x,y=\'z\'\\',alias=d,secret , default=[1,"2,]"] ,node=x=y`)
+	if assert.Nil(t, err) {
+		assert.Equal(t, []tagItem{
+			{pathKey, `"a.b".c`},
+			{optionalKey, "true"},
+			{defaultKey, `x,y='z'\`},
+			{aliasKey, "d"},
+			{secretKey, "true"},
+			{defaultKey, `[1,"2,]"]`},
+			{nodeKey, "x=y"},
+		}, items)
+	}
+
+	items, err = parseTag("")
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+
+	_, err = parseTag("path")
+	assert.EqualError(t, err, "tag format error: path: key path needs a value")
+
+	_, err = parseTag("secret,,optional")
+	assert.EqualError(t, err, "tag format error: secret,,optional: empty key at 7")
+
+	_, err = parseTag("default='a,b")
+	assert.EqualError(t, err, "tag format error: default='a,b: unclosed quote in value 'a,b")
+
+	_, err = parseTag("default='a'b")
+	assert.EqualError(t, err, "tag format error: default='a'b: unexpected b after quoted value at 11")
+
+	_, err = parseTag(`default="a`)
+	assert.EqualError(t, err, `tag format error: default="a: unclosed quote in value "a`)
+}
+
+func TestMapTagErrors(t *testing.T) {
+	_, err := mapTag(`hocon:"path=a,secret,required"`)
+	assert.EqualError(t, err, "tag format error: path=a,secret,required: unknown key required")
+
+	_, err = mapTag(`hocon:"path=a,path=b"`)
+	assert.EqualError(t, err, "tag format error: path=a,path=b: duplicate key path")
+
+	tagMap, err := mapTag(`hocon:"alias=a,alias=b"`)
+	if assert.Nil(t, err) {
+		assert.Equal(t, map[string]string{aliasKey: "b"}, tagMap)
+	}
+}
+
+func TestLoadWithTagGrammar(t *testing.T) {
+	var props struct {
+		List     string `hocon:"default='a,b'"`
+		Equation string `hocon:"default=x=y"`
+		URL      string `hocon:"default='http://host/?a=1&b=2'"`
+		Password string `hocon:"secret,optional"`
+	}
+	props.Password = "preset"
+	err := LoadConfigText("", &props)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "a,b", props.List)
+		assert.Equal(t, "x=y", props.Equation)
+		assert.Equal(t, "http://host/?a=1&b=2", props.URL)
+		assert.Equal(t, "preset", props.Password)
+		assert.Contains(t, Dump(props), `Password = "******"`)
+	}
+
+	var wrong struct {
+		A string `hocon:"defualt=x"`
+	}
+	err = LoadConfigText("A: a", &wrong)
+	assert.EqualError(t, err, "tag format error: defualt=x: unknown key defualt")
+}
@@ -0,0 +1,84 @@
+package hocon
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// discriminatorKey is the key of an object which names the implementation to decode the object to.
+const discriminatorKey = "type"
+
+// WithTypes registers implementations of the interface for polymorphic decoding. Interface is given as a nil
+// pointer to it, like (*Sink)(nil). Fields and slice elements of the interface type are decoded to the
+// implementation named by the `type` key of the object. Implementations are given by values or pointers like
+// KafkaSink{} or &KafkaSink{}, the field gets a value or a pointer accordingly.
+//
+//	hocon.WithTypes((*Sink)(nil), map[string]interface{}{"kafka": &KafkaSink{}, "stdout": &StdoutSink{}})
+func WithTypes(iface interface{}, types map[string]interface{}) Option {
+	return func(opts *options) {
+		ifaceType := reflect.TypeOf(iface).Elem()
+		if opts.types[ifaceType] == nil {
+			opts.types[ifaceType] = make(map[string]reflect.Type)
+		}
+		for name, prototype := range types {
+			opts.types[ifaceType][name] = reflect.TypeOf(prototype)
+		}
+	}
+}
+
+// isPolymorphicType tells if the type is an interface with registered implementations.
+func (opts *options) isPolymorphicType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Interface && opts.types[typ] != nil
+}
+
+// parsePolymorphic decodes the object to the implementation of the interface named by its `type` key.
+func (opts *options) parsePolymorphic(typ reflect.Type, hoconValue value) (reflect.Value, error) {
+	obj, ok := hoconValue.(*objectValue)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("cannot use %s as %s", typeName(hoconValue), typ)
+	}
+	discriminator, ok := obj.fields[discriminatorKey]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("no %s key to choose implementation of %s", discriminatorKey, typ)
+	}
+	name, ok := text(discriminator)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("cannot use %s as %s key", typeName(discriminator), discriminatorKey)
+	}
+
+	implementation, ok := opts.types[typ][name]
+	if !ok {
+		var known []string
+		for knownName := range opts.types[typ] {
+			known = append(known, knownName)
+		}
+		sort.Strings(known)
+		return reflect.Value{}, fmt.Errorf("unknown %s %s of %s, known ones are %s", discriminatorKey, name, typ,
+			strings.Join(known, ", "))
+	}
+	if !implementation.Implements(typ) {
+		return reflect.Value{}, fmt.Errorf("%s does not implement %s", implementation, typ)
+	}
+
+	structType := implementation
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("implementation %s of %s must be a struct", implementation, typ)
+	}
+
+	target := reflect.New(structType)
+	// fields of the implementation are not reported, their paths are relative to the object
+	nested := *opts
+	nested.report = nil
+	if err := loadConfig(obj, target.Interface(), &nested); err != nil {
+		return reflect.Value{}, fmt.Errorf("cannot load %s %s: %w", discriminatorKey, name, err)
+	}
+	if implementation.Kind() == reflect.Ptr {
+		return target, nil
+	}
+	return target.Elem(), nil
+}
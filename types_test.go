@@ -0,0 +1,94 @@
+package hocon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sink interface {
+	Write(text string) error
+}
+
+type kafkaSink struct {
+	Brokers []string
+	Topic   string `hocon:"default=events"`
+}
+
+func (*kafkaSink) Write(string) error {
+	return nil
+}
+
+type fileSink struct {
+	Path string `hocon:"node=file.path"`
+}
+
+func (fileSink) Write(string) error {
+	return nil
+}
+
+type stdoutSink struct{}
+
+func (stdoutSink) Write(string) error {
+	return nil
+}
+
+type sinkProperties struct {
+	Main  sink
+	Sinks []sink
+}
+
+var sinkTypes = WithTypes((*sink)(nil), map[string]interface{}{
+	"kafka":  &kafkaSink{},
+	"file":   fileSink{},
+	"stdout": stdoutSink{},
+})
+
+func TestLoadPolymorphic(t *testing.T) {
+	var props sinkProperties
+	err := LoadConfigText(`
+Main { type = stdout }
+Sinks = [
+  { type = kafka, Brokers = [a, b] }
+  { type = file, file.path = /var/log/app.log }
+]`, &props, sinkTypes)
+	if assert.Nil(t, err) {
+		assert.Equal(t, sinkProperties{
+			Main: stdoutSink{},
+			Sinks: []sink{
+				&kafkaSink{Brokers: []string{"a", "b"}, Topic: "events"},
+				fileSink{Path: "/var/log/app.log"},
+			},
+		}, props)
+	}
+}
+
+func TestLoadPolymorphicErrors(t *testing.T) {
+	var props sinkProperties
+	err := LoadConfigText("Main { Topic = x }, Sinks = []", &props, sinkTypes)
+	assert.EqualError(t, err, "wrong value for Main [] at 1:6: no type key to choose implementation of hocon.sink")
+
+	err = LoadConfigText("Main { type = http }, Sinks = []", &props, sinkTypes)
+	assert.EqualError(t, err, "wrong value for Main [] at 1:6: unknown type http of hocon.sink, "+
+		"known ones are file, kafka, stdout")
+
+	err = LoadConfigText("Main { type = [] }, Sinks = []", &props, sinkTypes)
+	assert.EqualError(t, err, "wrong value for Main [] at 1:6: cannot use array as type key")
+
+	err = LoadConfigText("Main = stdout, Sinks = []", &props, sinkTypes)
+	assert.EqualError(t, err, "wrong value for Main [] at 1:8: cannot use string as hocon.sink")
+
+	err = LoadConfigText("Main { type = stdout }, Sinks = [{ type = kafka }]", &props, sinkTypes)
+	assert.EqualError(t, err, "wrong value for Sinks [] at 1:34: cannot load type kafka: "+
+		"no value either default value provided for Brokers []")
+
+	wrong := WithTypes((*sink)(nil), map[string]interface{}{"kafka": kafkaSink{}})
+	err = LoadConfigText("Main { type = kafka }, Sinks = []", &props, wrong)
+	assert.EqualError(t, err, "wrong value for Main [] at 1:6: hocon.kafkaSink does not implement hocon.sink")
+
+	var withDefault struct {
+		Main sink `hocon:"default=stdout"`
+	}
+	err = LoadConfigText("", &withDefault, sinkTypes)
+	assert.EqualError(t, err, "interfaces do not support default value: Main [hocon:\"default=stdout\"]")
+}
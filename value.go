@@ -0,0 +1,408 @@
+package hocon
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// value is a node of the parsed HOCON tree. Objects, arrays and scalars are final values, substitutions,
+// concatenations and delayed merges are unresolved ones which are replaced with final values by resolver.
+type value interface {
+	pos() Origin
+}
+
+// Origin describes where a value comes from: the position in a file or text, the environment variable
+// if the value was substituted from environment, the resolver substitution like `secret:db/password` if the
+// value was provided by a resolver, and the chain of includes which led to the file.
+type Origin struct {
+	Filename     string
+	Line         int
+	Column       int
+	Env          string
+	Resolver     string
+	IncludedFrom *Origin
+}
+
+func (origin Origin) pos() Origin {
+	return origin
+}
+
+// String returns human readable origin like `app.conf:3:5` or `env HOME at app.conf:3:5`, followed by the
+// chain of includes.
+func (origin Origin) String() string {
+	var result string
+	if origin.Filename == "" {
+		result = fmt.Sprintf("%d:%d", origin.Line, origin.Column)
+	} else {
+		result = fmt.Sprintf("%s:%d:%d", origin.Filename, origin.Line, origin.Column)
+	}
+	if origin.Env != "" {
+		result = fmt.Sprintf("env %s at %s", origin.Env, result)
+	}
+	if origin.Resolver != "" {
+		result = fmt.Sprintf("resolver %s at %s", origin.Resolver, result)
+	}
+	for included := origin.IncludedFrom; included != nil; included = included.IncludedFrom {
+		if included.Filename == "" {
+			result += fmt.Sprintf(", included from %d:%d", included.Line, included.Column)
+		} else {
+			result += fmt.Sprintf(", included from %s:%d:%d", included.Filename, included.Line, included.Column)
+		}
+	}
+	return result
+}
+
+// objectValue is a HOCON object. Keys keep the order of their first appearance.
+type objectValue struct {
+	Origin
+	keys   []string
+	fields map[string]value
+}
+
+type arrayValue struct {
+	Origin
+	elements []value
+}
+
+// stringValue is a quoted or unquoted string. Whitespace-only strings between concatenated values are marked
+// by ws flag, they are dropped when an object or array concatenation is resolved.
+type stringValue struct {
+	Origin
+	text string
+	ws   bool
+}
+
+// numberValue keeps raw text of a number, it is parsed to the target type only while loading a field.
+type numberValue struct {
+	Origin
+	text string
+}
+
+type booleanValue struct {
+	Origin
+	value bool
+}
+
+type nullValue struct {
+	Origin
+}
+
+// substValue is a substitution ${path} or ${?path}. Self-referential substitutions which have no previous
+// value to refer to are marked as envOnly and can be resolved from environment variables only. Substitutions
+// like ${file:/path} have no path but resolver name and its argument. Substitutions of included configurations
+// have the path of include directive as a prefix of their paths, prefixLen is the length of the prefix.
+type substValue struct {
+	Origin
+	path      []string
+	prefixLen int
+	optional  bool
+	envOnly   bool
+	appending bool
+	resolver  string
+	argument  string
+}
+
+// concatValue is a value concatenation like `foo ${bar} baz` or `${list} [1, 2]`.
+type concatValue struct {
+	Origin
+	parts []value
+}
+
+// mergeValue is a chain of values assigned to the same key which cannot be merged before substitutions
+// are resolved. Later parts override earlier ones.
+type mergeValue struct {
+	Origin
+	parts []value
+}
+
+func newObject(pos Origin) *objectValue {
+	return &objectValue{Origin: pos, fields: make(map[string]value)}
+}
+
+// copy returns shallow copy of the object, nested values are shared.
+func (obj *objectValue) copy() *objectValue {
+	result := newObject(obj.Origin)
+	for _, key := range obj.keys {
+		result.set(key, obj.fields[key])
+	}
+	return result
+}
+
+// set puts the value to the object replacing existing one.
+func (obj *objectValue) set(key string, v value) {
+	if _, exists := obj.fields[key]; !exists {
+		obj.keys = append(obj.keys, key)
+	}
+	obj.fields[key] = v
+}
+
+// merge puts the value to the object merging it with existing one.
+func (obj *objectValue) merge(key string, v value) {
+	if prev, exists := obj.fields[key]; exists {
+		v = mergeValues(prev, v)
+	}
+	obj.set(key, v)
+}
+
+// find returns the value by given path or nil if there is no such path. Unresolved values are not
+// looked into.
+func (obj *objectValue) find(path []string) value {
+	var current value = obj
+	for _, key := range path {
+		container, ok := current.(*objectValue)
+		if !ok {
+			return nil
+		}
+		if current, ok = container.fields[key]; !ok {
+			return nil
+		}
+	}
+	return current
+}
+
+// isResolved tells if the value does not need to be processed by resolver to become final.
+func isResolved(v value) bool {
+	switch v.(type) {
+	case *substValue, *concatValue, *mergeValue:
+		return false
+	}
+	return true
+}
+
+// mergeValues merges next value into prev one according to HOCON duplicate keys rules: objects are merged
+// recursively, any other value overrides previous one. Merging of unresolved values is delayed until they are
+// resolved.
+func mergeValues(prev, next value) value {
+	switch n := next.(type) {
+	case *objectValue:
+		switch p := prev.(type) {
+		case *objectValue:
+			result := p.copy()
+			for _, key := range n.keys {
+				result.merge(key, n.fields[key])
+			}
+			return result
+		case *mergeValue:
+			return &mergeValue{Origin: p.Origin, parts: append(append([]value{}, p.parts...), n)}
+		case *substValue, *concatValue:
+			return &mergeValue{Origin: prev.pos(), parts: []value{prev, n}}
+		case *arrayValue:
+			if isIndexed(n) {
+				// merging by index fails for wrong indexes, so it is done by resolver which reports errors
+				return &mergeValue{Origin: p.Origin, parts: []value{p, n}}
+			}
+		}
+	case *substValue, *concatValue, *mergeValue:
+		// even a scalar previous value must be kept because an optional substitution may have no value
+		if p, ok := prev.(*mergeValue); ok {
+			return &mergeValue{Origin: p.Origin, parts: append(append([]value{}, p.parts...), n)}
+		}
+		return &mergeValue{Origin: prev.pos(), parts: []value{prev, n}}
+	}
+	return next
+}
+
+// mergeIndexed merges the object with numeric keys, like `a.1 = q`, over the list: elements are merged by
+// their indexes, the index right past the end of the list appends the element. Larger indexes would leave gaps
+// in the list, they are errors.
+func mergeIndexed(list *arrayValue, obj *objectValue) (*arrayValue, error) {
+	indexes := make(map[int]string, len(obj.keys))
+	var order []int
+	for _, key := range obj.keys {
+		// keys of indexed objects are made of digits, so they are indexes unless they are wrong
+		index, _, err := listIndex(key)
+		if err != nil {
+			return nil, newParseError(obj.fields[key].pos(), "%s", err)
+		}
+		indexes[index] = key
+		order = append(order, index)
+	}
+	sort.Ints(order)
+
+	result := &arrayValue{Origin: list.Origin, elements: append([]value{}, list.elements...)}
+	for _, index := range order {
+		element := obj.fields[indexes[index]]
+		switch {
+		case index < len(result.elements):
+			result.elements[index] = mergeValues(result.elements[index], element)
+		case index == len(result.elements):
+			result.elements = append(result.elements, element)
+		default:
+			return nil, newParseError(element.pos(), "index %d is past the end of the list of %d elements", index,
+				len(result.elements))
+		}
+	}
+	return result, nil
+}
+
+// isIndexed tells if the object has keys and all of them are made of digits, like `a.0 = x, a.1 = y`. Such
+// objects are merged over lists by index.
+func isIndexed(obj *objectValue) bool {
+	for _, key := range obj.keys {
+		if key == "" || strings.TrimLeft(key, "0123456789") != "" {
+			return false
+		}
+	}
+	return len(obj.keys) > 0
+}
+
+// listIndex parses the key of numerically-indexed object. Only non-negative integers like `0` or `12` are
+// indexes, it returns false for other keys like `x` or `+1`. Integers with leading zeros like `01` are errors
+// as they are ambiguous.
+func listIndex(key string) (int, bool, error) {
+	if key == "" || strings.TrimLeft(key, "0123456789") != "" {
+		return 0, false, nil
+	}
+	if len(key) > 1 && key[0] == '0' {
+		return 0, false, fmt.Errorf("wrong index %s, leading zeros are not allowed", key)
+	}
+	index, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, false, fmt.Errorf("wrong index %s: %w", key, err)
+	}
+	return index, true, nil
+}
+
+// text returns string representation of a scalar value and false for objects and arrays.
+func text(v value) (string, bool) {
+	switch typed := v.(type) {
+	case *stringValue:
+		return typed.text, true
+	case *numberValue:
+		return typed.text, true
+	case *booleanValue:
+		if typed.value {
+			return "true", true
+		}
+		return "false", true
+	case *nullValue:
+		return "null", true
+	}
+	return "", false
+}
+
+// toInterface converts the value to JSON-like representation: map[string]interface{}, []interface{}, string,
+// int64, float64, bool or nil. Numbers which do not fit int64 become float64.
+func toInterface(v value) interface{} {
+	switch typed := v.(type) {
+	case *objectValue:
+		result := make(map[string]interface{}, len(typed.keys))
+		for _, key := range typed.keys {
+			result[key] = toInterface(typed.fields[key])
+		}
+		return result
+	case *arrayValue:
+		result := make([]interface{}, 0, len(typed.elements))
+		for _, element := range typed.elements {
+			result = append(result, toInterface(element))
+		}
+		return result
+	case *numberValue:
+		if i, err := strconv.ParseInt(typed.text, 10, 64); err == nil {
+			return i
+		}
+		f, _ := strconv.ParseFloat(typed.text, 64)
+		return f
+	case *booleanValue:
+		return typed.value
+	case *nullValue:
+		return nil
+	}
+	s, _ := text(v)
+	return s
+}
+
+// typeName returns human readable name of the value type to be used in error messages.
+func typeName(v value) string {
+	switch v.(type) {
+	case *objectValue:
+		return "object"
+	case *arrayValue:
+		return "array"
+	case *stringValue:
+		return "string"
+	case *numberValue:
+		return "number"
+	case *booleanValue:
+		return "boolean"
+	case *nullValue:
+		return "null"
+	}
+	return "unresolved value"
+}
+
+// parsePath parses HOCON path expression like `a.b."c.d"` to path elements.
+func parsePath(expression string) ([]string, error) {
+	tokens, err := newLexer("", expression).lexPath()
+	if err != nil {
+		return nil, err
+	}
+	path, err := pathFromTokens(tokens)
+	if err != nil {
+		return nil, err
+	}
+	if path == nil {
+		return nil, fmt.Errorf("empty path expression")
+	}
+	return path, nil
+}
+
+// renderPath renders path elements back to a path expression, quoting elements when needed.
+func renderPath(path []string) string {
+	rendered := make([]string, len(path))
+	for i, key := range path {
+		if key == "" || strings.ContainsAny(key, forbiddenChars+".\"\\ \t\n") {
+			rendered[i] = quoteString(key)
+		} else {
+			rendered[i] = key
+		}
+	}
+	return strings.Join(rendered, ".")
+}
+
+// quoteString renders the string as a JSON-like quoted string.
+func quoteString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		default:
+			if r < 0x20 {
+				sb.WriteString(fmt.Sprintf(`\u%04x`, r))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// hasPrefix tells if the path starts with given prefix.
+func hasPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}